@@ -0,0 +1,63 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Srlinux condition types.
+const (
+	// ConditionInitialized is True when the init container of the pod has completed successfully
+	// and False when it has failed.
+	ConditionInitialized = "Initialized"
+	// ConditionRestarted is True when the SR Linux container has been restarted.
+	// The reason of the condition is the reason of the last termination, e.g. OOMKilled or Error.
+	ConditionRestarted = "Restarted"
+	// ConditionDrifted is True when the node config drifted from the startup config
+	// and False when it matches, as of the last drift check.
+	ConditionDrifted = "Drifted"
+	// ConditionVersionDiscovered is True when the SR Linux version was discovered from the node
+	// and False when the discovery failed and the version parsed from the spec is used instead.
+	ConditionVersionDiscovered = "VersionDiscovered"
+	// ConditionInterfacesValid is False when the interfaces declared in the spec are invalid
+	// and the pod can't be created, the message tells why.
+	ConditionInterfacesValid = "InterfacesValid"
+	// ConditionStartupConfigSourcesFetched is False when the startup config sources couldn't be fetched
+	// or merged and the pod can't be created, the message tells why.
+	ConditionStartupConfigSourcesFetched = "StartupConfigSourcesFetched"
+)
+
+// Srlinux condition reasons.
+const (
+	ReasonInitCompleted = "InitCompleted"
+	ReasonNotRestarted  = "NotRestarted"
+	// ReasonOOMKilled is the termination reason set by the kubelet when the container exceeds its memory limit.
+	ReasonOOMKilled         = "OOMKilled"
+	ReasonConfigDrifted     = "ConfigDrifted"
+	ReasonConfigInSync      = "ConfigInSync"
+	ReasonRemediated        = "Remediated"
+	ReasonDiscovered        = "Discovered"
+	ReasonDiscoveryFailed   = "DiscoveryFailed"
+	ReasonInvalidInterfaces = "InvalidInterfaces"
+	ReasonValidInterfaces   = "ValidInterfaces"
+	ReasonFetched           = "Fetched"
+	ReasonFetchFailed       = "FetchFailed"
+	ReasonInvalidSources    = "InvalidSources"
+)
+
+// TerminationStatus describes the termination of the SR Linux container.
+type TerminationStatus struct {
+	// Reason is the reason of the termination, e.g. OOMKilled, Error.
+	Reason string `json:"reason,omitempty"`
+	// Message is the message of the termination.
+	Message string `json:"message,omitempty"`
+	// ExitCode is the exit code of the container.
+	ExitCode int32 `json:"exit-code"`
+	// OOMKilled is true when the container was killed for exceeding its memory limit.
+	OOMKilled bool `json:"oom-killed,omitempty"`
+	// FinishedAt is the time the container terminated at.
+	FinishedAt metav1.Time `json:"finished-at,omitempty"`
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExportConfigAnnotation requests the export of the running config of the node to a ConfigMap.
+// The export is made once for every new value of the annotation, e.g. a timestamp.
+const ExportConfigAnnotation = "kne.srlinux.dev/export-config"
+
+// ConfigExportStatus is the status of the last export of the running config.
+type ConfigExportStatus struct {
+	// Request is the value of the export annotation the export was made for.
+	Request string `json:"request,omitempty"`
+	// ConfigMap is the name of the ConfigMap holding the exported config
+	// in the JSON (config.json) and the CLI flat (config.cli) formats.
+	ConfigMap string `json:"config-map,omitempty"`
+	// Time is the time the running config was exported at.
+	Time *metav1.Time `json:"time,omitempty"`
+	// Message describes why the export failed.
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const defaultDriftIntervalSeconds = 300
+
+// DriftSpec configures the periodic check of the node config drifting from the startup config
+// the node was provisioned with.
+type DriftSpec struct {
+	// IntervalSeconds is the interval the drift is checked at. Defaults to 300.
+	// +kubebuilder:validation:Minimum=30
+	IntervalSeconds int32 `json:"interval-seconds,omitempty"`
+	// IgnorePaths are the config paths in the flat format, e.g. "/ system information",
+	// the changes under which are not considered a drift.
+	IgnorePaths []string `json:"ignore-paths,omitempty"`
+	// Remediate reverts the node config to the startup config when a drift is detected.
+	Remediate bool `json:"remediate,omitempty"`
+}
+
+// DriftStatus is the result of the last drift check.
+type DriftStatus struct {
+	// Drifted is true when the node config differs from the startup config.
+	Drifted bool `json:"drifted"`
+	// Added is the number of config lines present on the node but not in the startup config.
+	Added int32 `json:"added,omitempty"`
+	// Removed is the number of config lines of the startup config missing on the node.
+	Removed int32 `json:"removed,omitempty"`
+	// Diff summarizes the drift with the first differing config lines,
+	// prefixed with "+" when added and "-" when removed.
+	Diff []string `json:"diff,omitempty"`
+	// Message describes why the drift couldn't be checked or remediated.
+	Message string `json:"message,omitempty"`
+	// LastCheckTime is the time of the last drift check.
+	LastCheckTime *metav1.Time `json:"last-check-time,omitempty"`
+	// LastRemediationTime is the time the node config was last reverted to the startup config.
+	LastRemediationTime *metav1.Time `json:"last-remediation-time,omitempty"`
+}
+
+// GetInterval returns the interval the drift is checked at.
+func (d *DriftSpec) GetInterval() time.Duration {
+	if d.IntervalSeconds == 0 {
+		return defaultDriftIntervalSeconds * time.Second
+	}
+
+	return time.Duration(d.IntervalSeconds) * time.Second
+}
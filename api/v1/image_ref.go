@@ -0,0 +1,105 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidImageReference = errors.New("invalid image reference")
+
+//nolint:gochecknoglobals
+var (
+	// path component of a repository name as per OCI distribution spec.
+	repoComponentRe = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+	tagRe           = regexp.MustCompile(`^\w[\w.-]{0,127}$`)
+	digestRe        = regexp.MustCompile(`^[a-z0-9]+([+._-][a-z0-9]+)*:[a-zA-Z0-9=_-]+$`)
+)
+
+// ImageReference represents a parsed OCI image reference
+// in the form of [registry/]repository[:tag][@digest].
+type ImageReference struct {
+	// Registry is the registry host with an optional port, e.g. ghcr.io or registry:5000.
+	// Empty when the reference doesn't contain a registry.
+	Registry string `json:"registry,omitempty"`
+	// Repository is the repository path within the registry, e.g. nokia/srlinux.
+	Repository string `json:"repository,omitempty"`
+	// Tag is the image tag, e.g. 23.10.1.
+	Tag string `json:"tag,omitempty"`
+	// Digest is the content addressable digest of the image, e.g. sha256:...
+	Digest string `json:"digest,omitempty"`
+}
+
+// ParseImageReference parses an image reference string.
+// Registry is recognized as the first path component when it contains a dot or a port,
+// or equals to localhost, which allows registries with ports such as registry:5000/srlinux:23.10.1.
+func ParseImageReference(s string) (*ImageReference, error) {
+	ref := &ImageReference{}
+
+	name := s
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+
+		if !digestRe.MatchString(ref.Digest) {
+			return nil, fmt.Errorf("%w: invalid digest in %q", ErrInvalidImageReference, s)
+		}
+	}
+
+	// tag is separated by a colon which comes after the last slash,
+	// otherwise the colon separates a registry port
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+
+		if !tagRe.MatchString(ref.Tag) {
+			return nil, fmt.Errorf("%w: invalid tag in %q", ErrInvalidImageReference, s)
+		}
+	}
+
+	if first, rest, found := strings.Cut(name, "/"); found &&
+		(strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry = first
+		name = rest
+	}
+
+	ref.Repository = name
+
+	for _, c := range strings.Split(ref.Repository, "/") {
+		if !repoComponentRe.MatchString(c) {
+			return nil, fmt.Errorf("%w: invalid repository in %q", ErrInvalidImageReference, s)
+		}
+	}
+
+	return ref, nil
+}
+
+// Name returns the image name without tag and digest, i.e. [registry/]repository.
+func (r *ImageReference) Name() string {
+	if r.Registry != "" {
+		return r.Registry + "/" + r.Repository
+	}
+
+	return r.Repository
+}
+
+// String returns the image reference in the form of [registry/]repository[:tag][@digest].
+func (r *ImageReference) String() string {
+	s := r.Name()
+
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+
+	return s
+}
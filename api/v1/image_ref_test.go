@@ -0,0 +1,132 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testDigest = "sha256:0f4c5d2e8a9b1c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		desc string
+		got  string
+		want *ImageReference
+		err  error
+	}{
+		{
+			desc: "registry, repository and tag",
+			got:  "ghcr.io/nokia/srlinux:23.10.1",
+			want: &ImageReference{Registry: "ghcr.io", Repository: "nokia/srlinux", Tag: "23.10.1"},
+		},
+		{
+			desc: "repository without registry",
+			got:  "srlinux:latest",
+			want: &ImageReference{Repository: "srlinux", Tag: "latest"},
+		},
+		{
+			desc: "docker hub style repository",
+			got:  "nokia/srlinux:0.0.0-38566",
+			want: &ImageReference{Repository: "nokia/srlinux", Tag: "0.0.0-38566"},
+		},
+		{
+			desc: "registry with port",
+			got:  "registry:5000/srlinux:23.10.1",
+			want: &ImageReference{Registry: "registry:5000", Repository: "srlinux", Tag: "23.10.1"},
+		},
+		{
+			desc: "registry with port, no tag",
+			got:  "registry.lab.local:5000/nokia/srlinux",
+			want: &ImageReference{Registry: "registry.lab.local:5000", Repository: "nokia/srlinux"},
+		},
+		{
+			desc: "localhost registry",
+			got:  "localhost/srlinux:23.10.1",
+			want: &ImageReference{Registry: "localhost", Repository: "srlinux", Tag: "23.10.1"},
+		},
+		{
+			desc: "digest only",
+			got:  "ghcr.io/nokia/srlinux@" + testDigest,
+			want: &ImageReference{Registry: "ghcr.io", Repository: "nokia/srlinux", Digest: testDigest},
+		},
+		{
+			desc: "tag and digest",
+			got:  "ghcr.io/nokia/srlinux:23.10.1@" + testDigest,
+			want: &ImageReference{
+				Registry:   "ghcr.io",
+				Repository: "nokia/srlinux",
+				Tag:        "23.10.1",
+				Digest:     testDigest,
+			},
+		},
+		{
+			desc: "registry with port, tag and digest",
+			got:  "registry:5000/srlinux:23.10.1@" + testDigest,
+			want: &ImageReference{
+				Registry:   "registry:5000",
+				Repository: "srlinux",
+				Tag:        "23.10.1",
+				Digest:     testDigest,
+			},
+		},
+		{
+			desc: "artifact registry",
+			got:  "us-west1-docker.pkg.dev/kne-external/kne/init-wait:ga",
+			want: &ImageReference{
+				Registry:   "us-west1-docker.pkg.dev",
+				Repository: "kne-external/kne/init-wait",
+				Tag:        "ga",
+			},
+		},
+		{
+			desc: "empty",
+			got:  "",
+			err:  ErrInvalidImageReference,
+		},
+		{
+			desc: "uppercase repository",
+			got:  "ghcr.io/nokia/SRLinux:23.10.1",
+			err:  ErrInvalidImageReference,
+		},
+		{
+			desc: "invalid digest",
+			got:  "ghcr.io/nokia/srlinux@sha256",
+			err:  ErrInvalidImageReference,
+		},
+		{
+			desc: "invalid tag",
+			got:  "ghcr.io/nokia/srlinux:-23.10",
+			err:  ErrInvalidImageReference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ref, err := ParseImageReference(tt.got)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if !cmp.Equal(ref, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					ref,
+					tt.want,
+				)
+			}
+
+			// valid references must be rendered back to the original string
+			if ref != nil && ref.String() != tt.got {
+				t.Fatalf("%s: reference rendered as %q", tt.desc, ref.String())
+			}
+		},
+		)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidInterface = errors.New("invalid interface")
+
+const (
+	minInterfaceMTU = 1500
+	maxInterfaceMTU = 9500
+)
+
+// srlIfNameRe matches SR Linux ethernet interface names, e.g. ethernet-1/1 or ethernet-1/3/1 for breakout ports.
+var srlIfNameRe = regexp.MustCompile(`^ethernet-(\d{1,2})/(\d{1,3})(?:/(\d{1,2}))?$`) //nolint:gochecknoglobals
+
+// InterfaceSpec defines an SR Linux interface and how it is attached to the pod.
+type InterfaceSpec struct {
+	// Name is the SR Linux interface name, e.g. ethernet-1/1.
+	// +kubebuilder:validation:Pattern=`^ethernet-\d{1,2}/\d{1,3}(/\d{1,2})?$`
+	Name string `json:"name"`
+	// Network is the name of a NetworkAttachmentDefinition in the form of [namespace/]name
+	// the interface is attached to with Multus.
+	// When not set, the interface is expected to be provisioned by other means (e.g. meshnet).
+	// Wiring the interface to a veth peer in another pod is not supported,
+	// links between nodes use a NetworkAttachmentDefinition shared by both ends.
+	Network string `json:"network,omitempty"`
+	// MTU is the port MTU configured on the SR Linux interface.
+	// +kubebuilder:validation:Minimum=1500
+	// +kubebuilder:validation:Maximum=9500
+	MTU int32 `json:"mtu,omitempty"`
+	// MAC is the MAC address of the interface inside the pod. Only applies to interfaces attached with Multus.
+	MAC string `json:"mac,omitempty"`
+}
+
+// InterfaceStatus is the rendered mapping between SR Linux and Linux interface names.
+type InterfaceStatus struct {
+	// Name is the SR Linux interface name, e.g. ethernet-1/1.
+	Name string `json:"name"`
+	// LinuxName is the name of the interface inside the pod, e.g. e1-1.
+	LinuxName string `json:"linux-name"`
+	// Network is the network attachment definition the interface is attached to.
+	Network string `json:"network,omitempty"`
+}
+
+// LinuxName returns the name of the interface inside the pod, e.g. e1-1 for ethernet-1/1
+// and e1-3-1 for ethernet-1/3/1.
+func (i *InterfaceSpec) LinuxName() (string, error) {
+	slot, port, breakout, err := i.position()
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("e%d-%d", slot, port)
+	if breakout != 0 {
+		name += fmt.Sprintf("-%d", breakout)
+	}
+
+	return name, nil
+}
+
+// position returns the slot, port and breakout port numbers of the interface.
+// The breakout port number is 0 for interfaces that are not breakout ports.
+func (i *InterfaceSpec) position() (slot, port, breakout int, err error) {
+	m := srlIfNameRe.FindStringSubmatch(i.Name)
+	if m == nil {
+		return 0, 0, 0, fmt.Errorf("%w: %q is not an SR Linux ethernet interface name", ErrInvalidInterface, i.Name)
+	}
+
+	slot, _ = strconv.Atoi(m[1])
+	port, _ = strconv.Atoi(m[2])
+	breakout, _ = strconv.Atoi(m[3])
+
+	return slot, port, breakout, nil
+}
+
+// validate checks that the interface fits into the variant capacity and its parameters are valid.
+// Capacity is not checked for variants with unknown capacity.
+func (i *InterfaceSpec) validate(c *variantProfile) error {
+	slot, port, breakout, err := i.position()
+	if err != nil {
+		return err
+	}
+
+	if slot < 1 || port < 1 {
+		return fmt.Errorf("%w: %q slot and port numbers start from 1", ErrInvalidInterface, i.Name)
+	}
+
+	if c != nil {
+		if slot > c.slots {
+			return fmt.Errorf("%w: %q slot exceeds %d slots of the variant", ErrInvalidInterface, i.Name, c.slots)
+		}
+
+		if port > c.ports {
+			return fmt.Errorf("%w: %q port exceeds %d ports of the variant", ErrInvalidInterface, i.Name, c.ports)
+		}
+
+		if breakout > c.breakouts {
+			return fmt.Errorf("%w: %q breakout port exceeds %d breakout ports of the variant",
+				ErrInvalidInterface, i.Name, c.breakouts)
+		}
+	}
+
+	if i.MTU != 0 && (i.MTU < minInterfaceMTU || i.MTU > maxInterfaceMTU) {
+		return fmt.Errorf("%w: %q mtu %d is out of %d-%d range",
+			ErrInvalidInterface, i.Name, i.MTU, minInterfaceMTU, maxInterfaceMTU)
+	}
+
+	if i.MAC != "" {
+		if _, err := net.ParseMAC(i.MAC); err != nil {
+			return fmt.Errorf("%w: %q invalid mac: %v", ErrInvalidInterface, i.Name, err) //nolint:errorlint
+		}
+	}
+
+	return nil
+}
+
+// NetworkNamespacedName returns the namespace and the name of the network attachment definition.
+// Namespace is empty when not set in the Network field.
+func (i *InterfaceSpec) NetworkNamespacedName() (namespace, name string) {
+	if ns, n, found := strings.Cut(i.Network, "/"); found {
+		return ns, n
+	}
+
+	return "", i.Network
+}
+
+// ValidateInterfaces validates the interfaces declared in the spec against
+// the port capacity of the SR Linux variant. It also checks that interfaces
+// are not declared more than once and that a port is not used both as a whole and broken out.
+func (s *SrlinuxSpec) ValidateInterfaces() error {
+	var c *variantProfile
+	if vp, ok := variantProfiles[s.GetModel()]; ok {
+		c = &vp
+	}
+
+	seen := map[string]bool{}
+	// ports that are used as a whole (false) or broken out (true)
+	ports := map[string]bool{}
+
+	for i := range s.Interfaces {
+		intf := &s.Interfaces[i]
+
+		if err := intf.validate(c); err != nil {
+			return err
+		}
+
+		if seen[intf.Name] {
+			return fmt.Errorf("%w: %q is declared more than once", ErrInvalidInterface, intf.Name)
+		}
+
+		seen[intf.Name] = true
+
+		slot, port, breakout, _ := intf.position()
+		key := fmt.Sprintf("%d/%d", slot, port)
+
+		if isBreakout, ok := ports[key]; ok && isBreakout != (breakout != 0) {
+			return fmt.Errorf("%w: port ethernet-%s is used both as a whole and broken out", ErrInvalidInterface, key)
+		}
+
+		ports[key] = breakout != 0
+	}
+
+	return nil
+}
+
+// GetInterfacesStatus returns the rendered mapping of SR Linux interface names to Linux interface names.
+func (s *SrlinuxSpec) GetInterfacesStatus() []InterfaceStatus {
+	var st []InterfaceStatus
+
+	for i := range s.Interfaces {
+		intf := &s.Interfaces[i]
+
+		name, err := intf.LinuxName()
+		if err != nil {
+			continue
+		}
+
+		st = append(st, InterfaceStatus{
+			Name:      intf.Name,
+			LinuxName: name,
+			Network:   intf.Network,
+		})
+	}
+
+	return st
+}
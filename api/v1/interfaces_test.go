@@ -0,0 +1,166 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInterfaceLinuxName(t *testing.T) {
+	tests := []struct {
+		desc string
+		got  string
+		want string
+		err  error
+	}{
+		{
+			desc: "ethernet interface",
+			got:  "ethernet-1/1",
+			want: "e1-1",
+		},
+		{
+			desc: "ethernet interface on a line card",
+			got:  "ethernet-2/34",
+			want: "e2-34",
+		},
+		{
+			desc: "breakout interface",
+			got:  "ethernet-1/3/1",
+			want: "e1-3-1",
+		},
+		{
+			desc: "linux interface name",
+			got:  "e1-1",
+			err:  ErrInvalidInterface,
+		},
+		{
+			desc: "mgmt interface",
+			got:  "mgmt0",
+			err:  ErrInvalidInterface,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			intf := &InterfaceSpec{Name: tt.got}
+
+			name, err := intf.LinuxName()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if !cmp.Equal(name, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					name,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
+
+func TestValidateInterfaces(t *testing.T) {
+	tests := []struct {
+		desc string
+		spec *SrlinuxSpec
+		err  error
+	}{
+		{
+			desc: "no interfaces",
+			spec: &SrlinuxSpec{},
+		},
+		{
+			desc: "interfaces fit default variant",
+			spec: &SrlinuxSpec{
+				Interfaces: []InterfaceSpec{
+					{Name: "ethernet-1/1", MTU: 9000, MAC: "02:00:00:00:00:01"},
+					{Name: "ethernet-1/3/1"},
+					{Name: "ethernet-1/3/2"},
+				},
+			},
+		},
+		{
+			desc: "port exceeds variant capacity",
+			spec: &SrlinuxSpec{
+				Model:      "ixrd3l",
+				Interfaces: []InterfaceSpec{{Name: "ethernet-1/35"}},
+			},
+			err: ErrInvalidInterface,
+		},
+		{
+			desc: "slot exceeds variant capacity",
+			spec: &SrlinuxSpec{
+				Model:      "ixr6e",
+				Interfaces: []InterfaceSpec{{Name: "ethernet-7/1"}},
+			},
+			err: ErrInvalidInterface,
+		},
+		{
+			desc: "line card interface on a chassis variant",
+			spec: &SrlinuxSpec{
+				Model:      "ixr10e",
+				Interfaces: []InterfaceSpec{{Name: "ethernet-10/36/8"}},
+			},
+		},
+		{
+			desc: "unknown variant skips capacity check",
+			spec: &SrlinuxSpec{
+				Model:      "ixr-custom",
+				Interfaces: []InterfaceSpec{{Name: "ethernet-12/99"}},
+			},
+		},
+		{
+			desc: "slot numbering starts from 1",
+			spec: &SrlinuxSpec{
+				Interfaces: []InterfaceSpec{{Name: "ethernet-0/1"}},
+			},
+			err: ErrInvalidInterface,
+		},
+		{
+			desc: "duplicate interface",
+			spec: &SrlinuxSpec{
+				Interfaces: []InterfaceSpec{{Name: "ethernet-1/1"}, {Name: "ethernet-1/1"}},
+			},
+			err: ErrInvalidInterface,
+		},
+		{
+			desc: "port used as a whole and broken out",
+			spec: &SrlinuxSpec{
+				Interfaces: []InterfaceSpec{{Name: "ethernet-1/1"}, {Name: "ethernet-1/1/1"}},
+			},
+			err: ErrInvalidInterface,
+		},
+		{
+			desc: "mtu out of range",
+			spec: &SrlinuxSpec{
+				Interfaces: []InterfaceSpec{{Name: "ethernet-1/1", MTU: 1000}},
+			},
+			err: ErrInvalidInterface,
+		},
+		{
+			desc: "invalid mac",
+			spec: &SrlinuxSpec{
+				Interfaces: []InterfaceSpec{{Name: "ethernet-1/1", MAC: "02:00:00"}},
+			},
+			err: ErrInvalidInterface,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.spec.ValidateInterfaces()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+		},
+		)
+	}
+}
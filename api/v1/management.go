@@ -0,0 +1,119 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Management endpoint names.
+const (
+	EndpointSSH     = "ssh"
+	EndpointGNMI    = "gnmi"
+	EndpointGNOI    = "gnoi"
+	EndpointJSONRPC = "json-rpc"
+	EndpointNETCONF = "netconf"
+)
+
+// defaultManagementEndpoints are the management endpoints SR Linux exposes by default.
+// gNOI service is served by the same gRPC server as gNMI.
+//
+//nolint:gochecknoglobals,gomnd
+var defaultManagementEndpoints = []ManagementEndpoint{
+	{Name: EndpointSSH, Port: 22},
+	{Name: EndpointGNMI, Port: 57400},
+	{Name: EndpointGNOI, Port: 57400},
+	{Name: EndpointJSONRPC, Port: 443},
+	{Name: EndpointNETCONF, Port: 830},
+}
+
+// defaultServicePorts are the management ports exposed by the node Service by default.
+//
+//nolint:gochecknoglobals,gomnd
+var defaultServicePorts = []ManagementEndpoint{
+	{Name: EndpointSSH, Port: 22},
+	{Name: EndpointGNMI, Port: 57400},
+	{Name: EndpointJSONRPC, Port: 443},
+	{Name: EndpointNETCONF, Port: 830},
+}
+
+// ServiceSpec defines the Service the controller creates for the node management endpoints.
+type ServiceSpec struct {
+	// Type is the type of the Service. Defaults to ClusterIP.
+	// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Ports are the management ports exposed by the Service.
+	// Defaults to ssh (22), gnmi (57400), json-rpc (443) and netconf (830).
+	Ports []ManagementEndpoint `json:"ports,omitempty"`
+	// Annotations to set on the Service, e.g. to request a specific address from a load balancer.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ManagementEndpoint is a management endpoint of the node.
+type ManagementEndpoint struct {
+	// Name of the endpoint, e.g. ssh, gnmi, gnoi, json-rpc, netconf.
+	Name string `json:"name"`
+	// Port the endpoint listens on.
+	Port int32 `json:"port"`
+}
+
+// ManagementStatus contains the management addresses and endpoints of the node.
+type ManagementStatus struct {
+	// IP is the management IP address of the node, which is the pod IP.
+	IP string `json:"ip,omitempty"`
+	// Endpoints are the management endpoints discovered on the management IP.
+	Endpoints []ManagementEndpoint `json:"endpoints,omitempty"`
+	// EndpointsProbeTime is the time the management endpoints were last probed at.
+	// The endpoints are probed again periodically to find endpoints started later.
+	EndpointsProbeTime *metav1.Time `json:"endpoints-probe-time,omitempty"`
+	// Service is the name of the Service that exposes the management endpoints.
+	Service string `json:"service,omitempty"`
+	// ServiceIP is the cluster IP of the Service.
+	ServiceIP string `json:"service-ip,omitempty"`
+	// ExternalIP is the address assigned to the Service by a load balancer.
+	ExternalIP string `json:"external-ip,omitempty"`
+}
+
+// GetManagementEndpoints returns the management endpoints of the node.
+// When the Service is set, the endpoints are the ports it exposes, with gNOI served on the gNMI port
+// unless listed on its own; the default endpoints are returned otherwise.
+func (s *SrlinuxSpec) GetManagementEndpoints() []ManagementEndpoint {
+	if s.Service == nil {
+		return defaultManagementEndpoints
+	}
+
+	ports := s.Service.GetPorts()
+	named := func(name string) func(ManagementEndpoint) bool {
+		return func(ep ManagementEndpoint) bool { return ep.Name == name }
+	}
+
+	gnmi := slices.IndexFunc(ports, named(EndpointGNMI))
+	if gnmi < 0 || slices.ContainsFunc(ports, named(EndpointGNOI)) {
+		return ports
+	}
+
+	return slices.Insert(slices.Clone(ports), gnmi+1, ManagementEndpoint{Name: EndpointGNOI, Port: ports[gnmi].Port})
+}
+
+// GetServiceType returns the type of the management Service, ClusterIP is returned if not set.
+func (s *ServiceSpec) GetServiceType() corev1.ServiceType {
+	if s.Type != "" {
+		return s.Type
+	}
+
+	return corev1.ServiceTypeClusterIP
+}
+
+// GetPorts returns the management ports exposed by the Service, default ports are returned if not set.
+func (s *ServiceSpec) GetPorts() []ManagementEndpoint {
+	if s.Ports != nil {
+		return s.Ports
+	}
+
+	return defaultServicePorts
+}
@@ -0,0 +1,160 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"k8s.io/utils/ptr"
+)
+
+// Liveness checks.
+const (
+	// LivenessCheckMgmtServer checks that the management server application is running.
+	LivenessCheckMgmtServer = "mgmt-server"
+	// LivenessCheckAppManager checks that the application manager is running.
+	LivenessCheckAppManager = "app-manager"
+)
+
+const (
+	startupPeriodSeconds = 5
+
+	defaultLivenessCheck = LivenessCheckMgmtServer
+)
+
+//nolint:gochecknoglobals,gomnd
+var (
+	defaultReadinessProbe = ProbeSpec{
+		InitialDelaySeconds: ptr.To[int32](10),
+		PeriodSeconds:       ptr.To[int32](5),
+		TimeoutSeconds:      ptr.To[int32](1),
+		FailureThreshold:    ptr.To[int32](10),
+	}
+
+	// sr_cli takes a few seconds to start, hence the timeout.
+	defaultLivenessProbe = ProbeSpec{
+		PeriodSeconds:    ptr.To[int32](10),
+		TimeoutSeconds:   ptr.To[int32](10),
+		FailureThreshold: ptr.To[int32](3),
+	}
+)
+
+// ProbesSpec defines the probes of the SR Linux container.
+type ProbesSpec struct {
+	// Startup probe waits for the management server to be ready for the time the variant takes to boot.
+	// Readiness and liveness probes start once the startup probe succeeds.
+	Startup *ProbeSpec `json:"startup,omitempty"`
+	// Readiness probe checks that the management server is ready to accept config.
+	Readiness *ProbeSpec `json:"readiness,omitempty"`
+	// Liveness probe restarts the container when the checked SR Linux application is not running.
+	// Liveness probe is not set up unless defined.
+	Liveness *LivenessProbeSpec `json:"liveness,omitempty"`
+}
+
+// ProbeSpec defines the timings of a probe. Unset timings take default values,
+// as do the period, timeout and failure threshold set to 0, which the API server would default otherwise.
+// Initial delay set to 0 is kept to start probing right away.
+type ProbeSpec struct {
+	// InitialDelaySeconds is the number of seconds after the container has started before the probe is initiated.
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds *int32 `json:"initial-delay-seconds,omitempty"`
+	// PeriodSeconds is how often to perform the probe.
+	// +kubebuilder:validation:Minimum=0
+	PeriodSeconds *int32 `json:"period-seconds,omitempty"`
+	// TimeoutSeconds is the number of seconds after which the probe times out.
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds *int32 `json:"timeout-seconds,omitempty"`
+	// FailureThreshold is the number of consecutive failures for the probe to be considered failed.
+	// +kubebuilder:validation:Minimum=0
+	FailureThreshold *int32 `json:"failure-threshold,omitempty"`
+}
+
+// LivenessProbeSpec defines the liveness probe of the SR Linux container.
+type LivenessProbeSpec struct {
+	ProbeSpec `json:",inline"`
+	// Check is the liveness check to perform. Can be one of:
+	// "mgmt-server" (default) to check the management server, "app-manager" to check the application manager.
+	// +kubebuilder:validation:Enum=mgmt-server;app-manager
+	Check string `json:"check,omitempty"`
+}
+
+// withDefaults returns the probe timings with unset timings taken from the defaults.
+// The period, timeout and failure threshold set to 0 are taken from the defaults as well.
+func (p *ProbeSpec) withDefaults(d ProbeSpec) ProbeSpec {
+	if p == nil {
+		return d
+	}
+
+	r := *p
+
+	if r.InitialDelaySeconds == nil {
+		r.InitialDelaySeconds = d.InitialDelaySeconds
+	}
+
+	if ptr.Deref(r.PeriodSeconds, 0) == 0 {
+		r.PeriodSeconds = d.PeriodSeconds
+	}
+
+	if ptr.Deref(r.TimeoutSeconds, 0) == 0 {
+		r.TimeoutSeconds = d.TimeoutSeconds
+	}
+
+	if ptr.Deref(r.FailureThreshold, 0) == 0 {
+		r.FailureThreshold = d.FailureThreshold
+	}
+
+	return r
+}
+
+// GetStartupProbe returns the startup probe timings.
+// By default, the probe waits for the boot time of the variant, or of a fixed form-factor variant
+// if the variant is not known. The boot time is tuned per variant only, not per SR Linux version.
+func (s *SrlinuxSpec) GetStartupProbe() ProbeSpec {
+	d := ProbeSpec{
+		PeriodSeconds:    ptr.To[int32](startupPeriodSeconds),
+		TimeoutSeconds:   ptr.To[int32](1),
+		FailureThreshold: ptr.To(s.bootSeconds() / startupPeriodSeconds),
+	}
+
+	if s.Probes == nil {
+		return d
+	}
+
+	return s.Probes.Startup.withDefaults(d)
+}
+
+// bootSeconds returns the time the node takes to boot given its variant.
+func (s *SrlinuxSpec) bootSeconds() int32 {
+	if vp, ok := variantProfiles[s.GetModel()]; ok {
+		return vp.bootSeconds
+	}
+
+	return fixedBootSeconds
+}
+
+// GetReadinessProbe returns the readiness probe timings.
+func (s *SrlinuxSpec) GetReadinessProbe() ProbeSpec {
+	if s.Probes == nil {
+		return defaultReadinessProbe
+	}
+
+	return s.Probes.Readiness.withDefaults(defaultReadinessProbe)
+}
+
+// GetLivenessProbe returns the liveness probe, nil is returned if liveness probe is not defined.
+func (s *SrlinuxSpec) GetLivenessProbe() *LivenessProbeSpec {
+	if s.Probes == nil || s.Probes.Liveness == nil {
+		return nil
+	}
+
+	p := &LivenessProbeSpec{
+		ProbeSpec: s.Probes.Liveness.ProbeSpec.withDefaults(defaultLivenessProbe),
+		Check:     s.Probes.Liveness.Check,
+	}
+
+	if p.Check == "" {
+		p.Check = defaultLivenessCheck
+	}
+
+	return p
+}
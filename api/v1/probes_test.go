@@ -0,0 +1,144 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+)
+
+// probeSpec returns the probe timings with the given values, negative values denote unset timings.
+func probeSpec(initialDelay, period, timeout, failure int32) ProbeSpec {
+	v := func(i int32) *int32 {
+		if i < 0 {
+			return nil
+		}
+
+		return ptr.To(i)
+	}
+
+	return ProbeSpec{
+		InitialDelaySeconds: v(initialDelay),
+		PeriodSeconds:       v(period),
+		TimeoutSeconds:      v(timeout),
+		FailureThreshold:    v(failure),
+	}
+}
+
+func TestGetStartupProbe(t *testing.T) {
+	tests := []struct {
+		desc string
+		spec *SrlinuxSpec
+		want ProbeSpec
+	}{
+		{
+			desc: "fixed form-factor variant",
+			spec: &SrlinuxSpec{Model: "ixrd3", Version: "23.10.1"},
+			want: probeSpec(-1, 5, 1, 60),
+		},
+		{
+			desc: "chassis variant boots longer",
+			spec: &SrlinuxSpec{Model: "ixr10e", Version: "23.10.1"},
+			want: probeSpec(-1, 5, 1, 180),
+		},
+		{
+			desc: "unknown variant",
+			spec: &SrlinuxSpec{Model: "ixr-x", Version: "23.10.1"},
+			want: probeSpec(-1, 5, 1, 60),
+		},
+		{
+			desc: "engineering build boots in the time of the variant",
+			spec: &SrlinuxSpec{Model: "ixrd3", Version: "latest"},
+			want: probeSpec(-1, 5, 1, 60),
+		},
+		{
+			desc: "timings set in the spec override the defaults",
+			spec: &SrlinuxSpec{
+				Model:   "ixr10e",
+				Version: "23.10.1",
+				Probes:  &ProbesSpec{Startup: &ProbeSpec{PeriodSeconds: ptr.To[int32](10), FailureThreshold: ptr.To[int32](30)}},
+			},
+			want: probeSpec(-1, 10, 1, 30),
+		},
+		{
+			desc: "zero initial delay kept, other zero timings defaulted",
+			spec: &SrlinuxSpec{
+				Model:   "ixrd3",
+				Version: "23.10.1",
+				Probes: &ProbesSpec{Startup: &ProbeSpec{
+					InitialDelaySeconds: ptr.To[int32](0),
+					PeriodSeconds:       ptr.To[int32](0),
+					TimeoutSeconds:      ptr.To[int32](0),
+					FailureThreshold:    ptr.To[int32](0),
+				}},
+			},
+			want: probeSpec(0, 5, 1, 60),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			p := tt.spec.GetStartupProbe()
+
+			if !cmp.Equal(p, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					p,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
+
+func TestGetLivenessProbe(t *testing.T) {
+	tests := []struct {
+		desc string
+		spec *SrlinuxSpec
+		want *LivenessProbeSpec
+	}{
+		{
+			desc: "liveness probe not defined",
+			spec: &SrlinuxSpec{},
+			want: nil,
+		},
+		{
+			desc: "defaults apply",
+			spec: &SrlinuxSpec{Probes: &ProbesSpec{Liveness: &LivenessProbeSpec{}}},
+			want: &LivenessProbeSpec{ProbeSpec: defaultLivenessProbe, Check: LivenessCheckMgmtServer},
+		},
+		{
+			desc: "app manager check with custom threshold",
+			spec: &SrlinuxSpec{Probes: &ProbesSpec{Liveness: &LivenessProbeSpec{
+				ProbeSpec: ProbeSpec{FailureThreshold: ptr.To[int32](6)},
+				Check:     LivenessCheckAppManager,
+			}}},
+			want: &LivenessProbeSpec{
+				ProbeSpec: probeSpec(-1, 10, 10, 6),
+				Check:     LivenessCheckAppManager,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			p := tt.spec.GetLivenessProbe()
+
+			if !cmp.Equal(p, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					p,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
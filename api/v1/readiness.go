@@ -0,0 +1,64 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import "fmt"
+
+const (
+	// ReadinessStageMgmtServer is the name of the readiness stage passed
+	// when the management server is ready to accept config.
+	ReadinessStageMgmtServer = "mgmt-server"
+
+	defaultBGPNetworkInstance = "default"
+)
+
+// ReadinessSpec defines the stages the node passes before it is considered ready.
+// The management server readiness is always checked first, the rest of the stages are
+// evaluated by the controller over the management session once the management server is ready.
+type ReadinessSpec struct {
+	// Apps is a list of SR Linux applications that need to be running, e.g. bgp_mgr.
+	Apps []string `json:"apps,omitempty"`
+	// Interfaces is a list of SR Linux interfaces that need to be operationally up, e.g. ethernet-1/1.
+	Interfaces []string `json:"interfaces,omitempty"`
+	// BGPNeighbors is a list of BGP neighbors that need to have their sessions established.
+	BGPNeighbors []BGPNeighborReadiness `json:"bgp-neighbors,omitempty"`
+}
+
+// BGPNeighborReadiness identifies the BGP neighbor which session needs to be established.
+type BGPNeighborReadiness struct {
+	// NetworkInstance is the network instance the neighbor belongs to. Defaults to "default".
+	NetworkInstance string `json:"network-instance,omitempty"`
+	// Address is the peer address of the neighbor.
+	Address string `json:"address"`
+}
+
+// ReadinessStageStatus is the status of a readiness stage.
+type ReadinessStageStatus struct {
+	// Name of the stage, e.g. mgmt-server, app/bgp_mgr, interface/ethernet-1/1, bgp/default/10.0.0.1.
+	Name string `json:"name"`
+	// Ready is true when the stage is passed.
+	Ready bool `json:"ready"`
+	// Message describes why the stage is not passed.
+	Message string `json:"message,omitempty"`
+}
+
+// HasStages returns true if any readiness stages in addition to the management server readiness are configured.
+func (r *ReadinessSpec) HasStages() bool {
+	return r != nil && (len(r.Apps) > 0 || len(r.Interfaces) > 0 || len(r.BGPNeighbors) > 0)
+}
+
+// GetNetworkInstance returns the network instance of the neighbor, "default" is returned if not set.
+func (n *BGPNeighborReadiness) GetNetworkInstance() string {
+	if n.NetworkInstance != "" {
+		return n.NetworkInstance
+	}
+
+	return defaultBGPNetworkInstance
+}
+
+// StageName returns the name of the readiness stage for the neighbor.
+func (n *BGPNeighborReadiness) StageName() string {
+	return fmt.Sprintf("bgp/%s/%s", n.GetNetworkInstance(), n.Address)
+}
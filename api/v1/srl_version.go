@@ -5,11 +5,16 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 )
 
+var ErrInvalidVersionConstraint = errors.New("invalid version constraint")
+
 // SrlVersion represents an sr linux version as a set of fields.
 type SrlVersion struct {
 	Major  string `json:"major,omitempty"`
@@ -38,3 +43,164 @@ func parseVersionString(s string) *SrlVersion {
 
 	return &SrlVersion{v[1], v[2], v[3], v[4], v[5]}
 }
+
+// String returns the version in the MAJOR.MINOR.PATCH-BUILD-COMMIT form
+// omitting the fields that are not set.
+func (v *SrlVersion) String() string {
+	s := v.Major
+
+	for _, f := range []string{v.Minor, v.Patch} {
+		if f == "" {
+			break
+		}
+
+		s += "." + f
+	}
+
+	for _, f := range []string{v.Build, v.Commit} {
+		if f != "" {
+			s += "-" + f
+		}
+	}
+
+	return s
+}
+
+// IsEngineering returns true when the version denotes an engineering build
+// or a version that couldn't be determined, e.g. `latest` or `0.0.0-34652`.
+// Such versions have major version set to 0.
+func (v *SrlVersion) IsEngineering() bool {
+	return v.Major == "0" || v.Major == ""
+}
+
+// Compare compares version v to version o numerically by major, minor, patch and build fields.
+// The fields that are not set are treated as zeroes and the commit field is ignored.
+// The result is 0 if v == o, -1 if v < o, and +1 if v > o.
+func (v *SrlVersion) Compare(o *SrlVersion) int {
+	a := v.fields()
+	b := o.fields()
+
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// fields returns the numeric values of major, minor, patch and build fields.
+func (v *SrlVersion) fields() [4]int {
+	var f [4]int
+
+	for i, s := range []string{v.Major, v.Minor, v.Patch, v.Build} {
+		f[i], _ = strconv.Atoi(s)
+	}
+
+	return f
+}
+
+// versionConstraint is a single version constraint such as `>=23.10`.
+// +kubebuilder:object:generate=false
+type versionConstraint struct {
+	op      string
+	version *SrlVersion
+}
+
+// VersionConstraints is a set of version constraints that all must be satisfied by a version.
+// +kubebuilder:object:generate=false
+type VersionConstraints []versionConstraint
+
+// ParseVersionConstraints parses a comma or space separated list of version constraints
+// in the form of `<op><version>`, where op is one of `=`, `==`, `!=`, `>`, `>=`, `<`, `<=`.
+// When op is omitted, `=` is assumed. Example: ">=22.11, <23.10".
+func ParseVersionConstraints(s string) (VersionConstraints, error) {
+	var cs VersionConstraints
+
+	re := regexp.MustCompile(`^(==|=|!=|>=|>|<=|<)?(\d{1,3}(\.\d{1,2}){0,2}(-\d{1,10})?)$`)
+
+	for _, c := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		m := re.FindStringSubmatch(c)
+		if m == nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidVersionConstraint, c)
+		}
+
+		op := m[1]
+		if op == "" || op == "==" {
+			op = "="
+		}
+
+		cs = append(cs, versionConstraint{op: op, version: parseVersionString(m[2])})
+	}
+
+	if cs == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidVersionConstraint, s)
+	}
+
+	return cs, nil
+}
+
+// MustParseVersionConstraints is like ParseVersionConstraints but panics if the constraints can't be parsed.
+func MustParseVersionConstraints(s string) VersionConstraints {
+	cs, err := ParseVersionConstraints(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return cs
+}
+
+// Check returns true if version v satisfies all constraints.
+func (cs VersionConstraints) Check(v *SrlVersion) bool {
+	for _, c := range cs {
+		if !c.check(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c versionConstraint) check(v *SrlVersion) bool {
+	switch c.op {
+	case "=":
+		return c.matches(v)
+	case "!=":
+		return !c.matches(v)
+	case ">":
+		return v.Compare(c.version) > 0
+	case ">=":
+		return v.Compare(c.version) >= 0
+	case "<":
+		return v.Compare(c.version) < 0
+	case "<=":
+		return v.Compare(c.version) <= 0
+	}
+
+	return false
+}
+
+// matches returns true if version v matches the constraint version
+// in all the fields that are set in the constraint version,
+// i.e. 23.10.1 matches `=23.10`, but 23.10 doesn't match `=23.10.1`.
+func (c versionConstraint) matches(v *SrlVersion) bool {
+	want := []string{c.version.Major, c.version.Minor, c.version.Patch, c.version.Build}
+	got := v.fields()
+
+	for i, s := range want {
+		if s == "" {
+			break
+		}
+
+		n, _ := strconv.Atoi(s)
+		if got[i] != n {
+			return false
+		}
+	}
+
+	return true
+}
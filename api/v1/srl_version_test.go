@@ -5,6 +5,7 @@
 package v1
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -104,3 +105,153 @@ func TestParseVersionString(t *testing.T) {
 		)
 	}
 }
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		desc string
+		a    string
+		b    string
+		want int
+	}{
+		{
+			desc: "equal versions",
+			a:    "23.10.1",
+			b:    "23.10.1",
+			want: 0,
+		},
+		{
+			desc: "missing patch is zero",
+			a:    "23.10",
+			b:    "23.10.0",
+			want: 0,
+		},
+		{
+			desc: "minor compared numerically",
+			a:    "23.3.1",
+			b:    "23.10.1",
+			want: -1,
+		},
+		{
+			desc: "major wins over minor",
+			a:    "24.3",
+			b:    "23.10.3",
+			want: 1,
+		},
+		{
+			desc: "build compared",
+			a:    "21.6.11-235",
+			b:    "21.6.11-99",
+			want: 1,
+		},
+		{
+			desc: "commit ignored",
+			a:    "21.6.11-235-abc",
+			b:    "21.6.11-235-def",
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := parseVersionString(tt.a).Compare(parseVersionString(tt.b))
+
+			if !cmp.Equal(got, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					got,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
+
+func TestVersionConstraints(t *testing.T) {
+	tests := []struct {
+		desc        string
+		constraints string
+		version     string
+		want        bool
+		err         error
+	}{
+		{
+			desc:        "greater or equal satisfied",
+			constraints: ">=23.10",
+			version:     "23.10.1",
+			want:        true,
+		},
+		{
+			desc:        "greater or equal not satisfied",
+			constraints: ">=23.10",
+			version:     "23.3.2",
+			want:        false,
+		},
+		{
+			desc:        "range satisfied",
+			constraints: ">=22.11, <23.10",
+			version:     "23.7.1",
+			want:        true,
+		},
+		{
+			desc:        "range upper bound not satisfied",
+			constraints: ">=22.11 <23.10",
+			version:     "23.10",
+			want:        false,
+		},
+		{
+			desc:        "equality matches set fields only",
+			constraints: "=23.10",
+			version:     "23.10.4",
+			want:        true,
+		},
+		{
+			desc:        "implicit equality",
+			constraints: "23.10.1",
+			version:     "23.10.4",
+			want:        false,
+		},
+		{
+			desc:        "not equal",
+			constraints: "!=23.10",
+			version:     "23.10.4",
+			want:        false,
+		},
+		{
+			desc:        "invalid operator",
+			constraints: "~23.10",
+			err:         ErrInvalidVersionConstraint,
+		},
+		{
+			desc:        "empty constraints",
+			constraints: "",
+			err:         ErrInvalidVersionConstraint,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cs, err := ParseVersionConstraints(tt.constraints)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			got := cs.Check(parseVersionString(tt.version))
+
+			if !cmp.Equal(got, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					got,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
@@ -33,7 +33,6 @@ package v1
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -89,6 +88,11 @@ func (s *SrlinuxSpec) GetImage() string {
 	return img
 }
 
+// GetImageReference returns the parsed reference of the srlinux container image.
+func (s *SrlinuxSpec) GetImageReference() (*ImageReference, error) {
+	return ParseImageReference(s.GetImage())
+}
+
 // GetInitImage gets init container image from the srlinux spec,
 // default init container image is returned if none present in the spec.
 func (s *SrlinuxSpec) GetInitImage() string {
@@ -102,8 +106,8 @@ func (s *SrlinuxSpec) GetInitImage() string {
 // GetImageVersion finds an srlinux image version by looking at the Image field of the spec
 // as well as at Version field.
 // When Version field is set it is returned.
-// In other cases, Image string is evaluated and it's tag substring is parsed.
-// If no tag is present, or tag is latest, the 0.0 version is assumed to be in use.
+// In other cases, Image reference is parsed and its tag is evaluated.
+// If no tag is present (e.g. digest-only references), or tag is latest, the 0.0 version is assumed to be in use.
 func (s *SrlinuxSpec) GetImageVersion() *SrlVersion {
 	if s.Version != "" {
 		return parseVersionString(s.Version)
@@ -111,14 +115,46 @@ func (s *SrlinuxSpec) GetImageVersion() *SrlVersion {
 
 	var tag string
 
-	split := strings.Split(s.GetImage(), ":")
-	if len(split) == 2 { //nolint:gomnd
-		tag = split[1]
+	if ref, err := s.GetImageReference(); err == nil {
+		tag = ref.Tag
 	}
 
 	return parseVersionString(tag)
 }
 
+// GetNumInterfaces returns the number of data interfaces of the node.
+// When interfaces are declared in the spec, their number takes precedence over NumInterfaces.
+func (s *SrlinuxSpec) GetNumInterfaces() int {
+	if len(s.Interfaces) > 0 {
+		return len(s.Interfaces)
+	}
+
+	return s.NumInterfaces
+}
+
+// GetVersionDiscovery returns the version discovery method from the srlinux spec,
+// device discovery is returned if none present in the spec.
+func (s *SrlinuxSpec) GetVersionDiscovery() string {
+	if s.VersionDiscovery != "" {
+		return s.VersionDiscovery
+	}
+
+	return VersionDiscoveryDevice
+}
+
+// GetVersion returns the SR Linux version of the node.
+// The version discovered from the booted node and recorded in the status takes precedence
+// over the version parsed from the spec when the latter couldn't be determined.
+func (s *Srlinux) GetVersion() *SrlVersion {
+	v := s.Spec.GetImageVersion()
+
+	if v.IsEngineering() && s.Status.Version != "" {
+		return parseVersionString(s.Status.Version)
+	}
+
+	return v
+}
+
 // InitLicenseKey sets the Srlinux.LicenseKey to a value of a key
 // that matches MAJOR-MINOR.key of a passed secret.
 // Where MAJOR-MINOR is retrieved from the image version.
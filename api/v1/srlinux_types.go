@@ -32,18 +32,45 @@ package v1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // SrlinuxSpec defines the desired state of Srlinux.
 type SrlinuxSpec struct {
-	Config        *NodeConfig       `json:"config,omitempty"`
-	NumInterfaces int               `json:"num-interfaces,omitempty"`
-	Constraints   map[string]string `json:"constraints,omitempty"`
+	Config        *NodeConfig `json:"config,omitempty"`
+	NumInterfaces int         `json:"num-interfaces,omitempty"`
+	// Interfaces is a list of SR Linux interfaces of the node.
+	// Interfaces that reference a network are attached to the pod with Multus,
+	// which allows wiring SR Linux nodes without meshnet.
+	Interfaces  []InterfaceSpec   `json:"interfaces,omitempty"`
+	Constraints map[string]string `json:"constraints,omitempty"`
 	// Model encodes SR Linux variant (ixr-d3, ixr-6e, etc)
 	Model string `json:"model,omitempty"`
 	// Version may be set in kne topology as a mean to explicitly provide version information
 	// in case it is not encoded in the image tag
 	Version string `json:"version,omitempty"`
+	// VersionDiscovery defines how SR Linux version is discovered when it can't be determined
+	// from the Version field or the image tag (e.g. latest, digests or custom tags).
+	// Can be one of: "device" (default) to query the version from the booted node, "none" to skip discovery.
+	// +kubebuilder:validation:Enum=device;none
+	VersionDiscovery string `json:"version-discovery,omitempty"`
+	// Service defines the Service exposing the node management endpoints.
+	// When set, the controller creates and owns a Service for the node.
+	Service *ServiceSpec `json:"service,omitempty"`
+	// Readiness defines the stages the node passes before it is considered ready.
+	// When not set, the node is ready once its management server is ready to accept config.
+	Readiness *ReadinessSpec `json:"readiness,omitempty"`
+	// Probes defines the startup, readiness and liveness probes of the SR Linux container.
+	// Probe timings default to the values suited for the variant.
+	Probes *ProbesSpec `json:"probes,omitempty"`
+	// StartupConfig defines the sources of the startup config in addition to the config provided by kne.
+	StartupConfig *StartupConfigSpec `json:"startup-config,omitempty"`
+	// Storage defines the persistent storage of the node state.
+	// When set, configs, checkpoints and logs survive pod restarts and re-creations,
+	// and the startup config is not re-applied to the node that has the persisted config.
+	Storage *StorageSpec `json:"storage,omitempty"`
+	// Drift enables the periodic check of the node config drifting from the startup config.
+	Drift *DriftSpec `json:"drift,omitempty"`
 }
 
 // SrlinuxStatus defines the observed state of Srlinux.
@@ -53,16 +80,65 @@ type SrlinuxStatus struct {
 	Status string `json:"status,omitempty"`
 	// Image used to run srlinux pod
 	Image string `json:"image,omitempty"`
+	// ImageReference is the parsed reference of the image used to run srlinux pod.
+	ImageReference *ImageReference `json:"image-reference,omitempty"`
+	// Version is the SR Linux version the node runs.
+	// It is either parsed from the spec or discovered from the booted node.
+	Version string `json:"version,omitempty"`
+	// VersionDiscoveryAttempts is the number of failed attempts to discover the version from the node.
+	// After the attempts are exhausted, the version parsed from the spec is used.
+	VersionDiscoveryAttempts int32 `json:"version-discovery-attempts,omitempty"`
 	// StartupConfig contains the status of the startup-config.
 	StartupConfig StartupConfigStatus `json:"startup-config,omitempty"`
+	// StorageClaim is the name of the PersistentVolumeClaim holding the node state.
+	StorageClaim string `json:"storage-claim,omitempty"`
+	// Management contains the management IP and endpoints of the node.
+	Management ManagementStatus `json:"management,omitempty"`
+	// Interfaces is the mapping of SR Linux interface names to interface names inside the pod.
+	Interfaces []InterfaceStatus `json:"interfaces,omitempty"`
+	// Certificate contains the status of the node TLS certificate.
+	Certificate *CertificateStatus `json:"certificate,omitempty"`
+	// Drift is the result of the last check of the node config drifting from the startup config.
+	Drift *DriftStatus `json:"drift,omitempty"`
+	// ConfigExport is the status of the last export of the running config requested with the export annotation.
+	ConfigExport *ConfigExportStatus `json:"config-export,omitempty"`
+	// Restarts is the number of times the SR Linux container of the current pod has been restarted.
+	Restarts int32 `json:"restarts,omitempty"`
+	// LastTermination describes the last termination of the SR Linux container.
+	LastTermination *TerminationStatus `json:"last-termination,omitempty"`
+	// Conditions describe the state of the pod running the node, e.g. init container failures and restarts.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Readiness contains the status of the readiness stages configured in the spec.
+	Readiness []ReadinessStageStatus `json:"readiness,omitempty"`
 	// Ready is true if the srlinux NOS is ready to receive config.
-	// This is when management server is running and initial commit is processed.
+	// This is when management server is running and initial commit is processed,
+	// and the readiness stages configured in the spec are passed.
 	Ready bool `json:"ready,omitempty"`
 }
 
 type StartupConfigStatus struct {
-	// Phase is the phase startup-config is in. Can be one of: "pending", "loaded", "not-provided", "failed".
+	// Phase is the phase startup-config is in.
+	// Can be one of: "pending", "loaded", "not-provided", "persisted", "retrying", "failed".
+	// A startup-config failed for a transient reason, e.g. an SSH or commit failure, is "retrying"
+	// until it is loaded or the attempts are exhausted.
 	Phase string `json:"phase,omitempty"`
+	// PodUID is the UID of the pod the startup-config was processed on.
+	// When the pod is re-created, the startup-config is processed anew.
+	PodUID types.UID `json:"pod-uid,omitempty"`
+	// Message describes why the startup-config failed, e.g. when its format is unknown.
+	Message string `json:"message,omitempty"`
+	// FailedCommand is the command that failed to load the startup-config on the node.
+	FailedCommand string `json:"failed-command,omitempty"`
+	// DeviceError is the error output of the failed command, truncated.
+	DeviceError string `json:"device-error,omitempty"`
+	// Attempts is the number of attempts made to load the startup-config.
+	Attempts int32 `json:"attempts,omitempty"`
+	// LastAttemptTime is the time of the last attempt to load the startup-config.
+	LastAttemptTime *metav1.Time `json:"last-attempt-time,omitempty"`
+	// NextRetryTime is the time of the next attempt to load the startup-config when it is "retrying".
+	NextRetryTime *metav1.Time `json:"next-retry-time,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -71,8 +147,10 @@ type StartupConfigStatus struct {
 // Srlinux is the Schema for the srlinuxes API.
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.image"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version",priority=1
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status"
 // +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="Restarts",type="integer",JSONPath=".status.restarts",priority=1
 // +kubebuilder:printcolumn:name="Config",type="string",JSONPath=".status.startup-config.phase"
 type Srlinux struct {
 	metav1.TypeMeta   `json:",inline"`
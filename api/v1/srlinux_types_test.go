@@ -57,6 +57,36 @@ func TestGetImage(t *testing.T) {
 			},
 			want: "ghcr.io/nokia/srlinux:latest",
 		},
+		{
+			desc: "image without tag, version present",
+			spec: &SrlinuxSpec{
+				Version: "23.10.1",
+				Config: &NodeConfig{
+					Image: "registry:5000/srlinux",
+				},
+			},
+			want: "registry:5000/srlinux",
+		},
+		{
+			desc: "image with digest, version present",
+			spec: &SrlinuxSpec{
+				Version: "23.10.1",
+				Config: &NodeConfig{
+					Image: "ghcr.io/nokia/srlinux@" + testDigest,
+				},
+			},
+			want: "ghcr.io/nokia/srlinux@" + testDigest,
+		},
+		{
+			desc: "image with tag, version present",
+			spec: &SrlinuxSpec{
+				Version: "23.10.1",
+				Config: &NodeConfig{
+					Image: "ghcr.io/nokia/srlinux:custom",
+				},
+			},
+			want: "ghcr.io/nokia/srlinux:custom",
+		},
 	}
 
 	for _, tt := range tests {
@@ -113,6 +143,34 @@ func TestGetImageVersion(t *testing.T) {
 			},
 			want: &SrlVersion{"0", "", "", "", ""},
 		},
+		{
+			desc: "version is not present, registry with port and valid tag",
+			spec: &SrlinuxSpec{
+				Config: &NodeConfig{Image: "registry:5000/srlinux:23.10.1"},
+			},
+			want: &SrlVersion{"23", "10", "1", "", ""},
+		},
+		{
+			desc: "version is not present, registry with port and no tag",
+			spec: &SrlinuxSpec{
+				Config: &NodeConfig{Image: "registry:5000/srlinux"},
+			},
+			want: &SrlVersion{"0", "", "", "", ""},
+		},
+		{
+			desc: "version is not present, digest only",
+			spec: &SrlinuxSpec{
+				Config: &NodeConfig{Image: "ghcr.io/nokia/srlinux@" + testDigest},
+			},
+			want: &SrlVersion{"0", "", "", "", ""},
+		},
+		{
+			desc: "version is not present, tag and digest",
+			spec: &SrlinuxSpec{
+				Config: &NodeConfig{Image: "ghcr.io/nokia/srlinux:24.3.2@" + testDigest},
+			},
+			want: &SrlVersion{"24", "3", "2", "", ""},
+		},
 	}
 
 	for _, tt := range tests {
@@ -300,3 +358,115 @@ func TestGetInitImage(t *testing.T) {
 		)
 	}
 }
+
+func TestGetVersion(t *testing.T) {
+	tests := []struct {
+		desc    string
+		srlinux *Srlinux
+		want    *SrlVersion
+	}{
+		{
+			desc: "version parsed from the image tag",
+			srlinux: &Srlinux{
+				Spec: SrlinuxSpec{
+					Config: &NodeConfig{Image: "ghcr.io/nokia/srlinux:23.10.1"},
+				},
+				Status: SrlinuxStatus{Version: "24.3.1"},
+			},
+			want: &SrlVersion{"23", "10", "1", "", ""},
+		},
+		{
+			desc: "version discovered from the node",
+			srlinux: &Srlinux{
+				Spec: SrlinuxSpec{
+					Config: &NodeConfig{Image: "ghcr.io/nokia/srlinux:latest"},
+				},
+				Status: SrlinuxStatus{Version: "23.10.1-218-ga3fc1bea5a"},
+			},
+			want: &SrlVersion{"23", "10", "1", "218", "ga3fc1bea5a"},
+		},
+		{
+			desc: "version not discovered yet",
+			srlinux: &Srlinux{
+				Spec: SrlinuxSpec{
+					Config: &NodeConfig{Image: "ghcr.io/nokia/srlinux:latest"},
+				},
+			},
+			want: &SrlVersion{"0", "", "", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			v := tt.srlinux.GetVersion()
+
+			if !cmp.Equal(v, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					v,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
+
+func TestGetManagementEndpoints(t *testing.T) {
+	tests := []struct {
+		desc string
+		spec *SrlinuxSpec
+		want []ManagementEndpoint
+	}{
+		{
+			desc: "no service",
+			spec: &SrlinuxSpec{},
+			want: defaultManagementEndpoints,
+		},
+		{
+			desc: "service with default ports",
+			spec: &SrlinuxSpec{Service: &ServiceSpec{}},
+			want: defaultManagementEndpoints,
+		},
+		{
+			desc: "service with custom ports",
+			spec: &SrlinuxSpec{Service: &ServiceSpec{Ports: []ManagementEndpoint{
+				{Name: EndpointSSH, Port: 22},
+				{Name: EndpointGNMI, Port: 50052},
+			}}},
+			want: []ManagementEndpoint{
+				{Name: EndpointSSH, Port: 22},
+				{Name: EndpointGNMI, Port: 50052},
+				{Name: EndpointGNOI, Port: 50052},
+			},
+		},
+		{
+			desc: "service with gNOI on its own port",
+			spec: &SrlinuxSpec{Service: &ServiceSpec{Ports: []ManagementEndpoint{
+				{Name: EndpointGNMI, Port: 57400},
+				{Name: EndpointGNOI, Port: 9339},
+			}}},
+			want: []ManagementEndpoint{
+				{Name: EndpointGNMI, Port: 57400},
+				{Name: EndpointGNOI, Port: 9339},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			eps := tt.spec.GetManagementEndpoints()
+
+			if !cmp.Equal(eps, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					eps,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const defaultCommandTimeoutSeconds = 30
+
+// SrlinuxCommand phases.
+const (
+	CommandPhasePending   = "pending"
+	CommandPhaseSucceeded = "succeeded"
+	CommandPhaseFailed    = "failed"
+)
+
+// SrlinuxCommandSpec defines the commands run on the Srlinux nodes.
+type SrlinuxCommandSpec struct {
+	// Nodes are the names of the Srlinux nodes in the namespace the commands are run on.
+	Nodes []string `json:"nodes,omitempty"`
+	// Selector selects the Srlinux nodes in the namespace the commands are run on by their labels,
+	// in addition to the Nodes.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Commands are the CLI commands run in order on every node in the running mode,
+	// e.g. "/tools interface ethernet-1/1 statistics clear".
+	// +kubebuilder:validation:MinItems=1
+	Commands []string `json:"commands"`
+	// ContinueOnError runs the remaining commands on a node after a command failed.
+	// By default, the commands following the failed one are not run.
+	ContinueOnError bool `json:"continue-on-error,omitempty"`
+	// TimeoutSeconds is the time a command is given to complete. Defaults to 30.
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int32 `json:"timeout-seconds,omitempty"`
+}
+
+// SrlinuxCommandStatus defines the observed state of SrlinuxCommand.
+type SrlinuxCommandStatus struct {
+	// Phase is the phase the commands are in.
+	// Can be one of: "pending", "succeeded", "failed".
+	// The commands are pending until they are run on every selected node,
+	// a node that is not ready yet is waited for.
+	Phase string `json:"phase,omitempty"`
+	// StartTime is the time the commands were first run on a node.
+	StartTime *metav1.Time `json:"start-time,omitempty"`
+	// CompletionTime is the time the commands were run on every node.
+	CompletionTime *metav1.Time `json:"completion-time,omitempty"`
+	// Nodes are the results of the commands per node.
+	// +listType=map
+	// +listMapKey=node
+	Nodes []NodeCommandStatus `json:"nodes,omitempty"`
+}
+
+// NodeCommandStatus is the result of the commands run on a node.
+type NodeCommandStatus struct {
+	// Node is the name of the Srlinux node.
+	Node string `json:"node"`
+	// Phase is the phase the commands are in on the node.
+	// Can be one of: "pending", "succeeded", "failed".
+	Phase string `json:"phase"`
+	// Message describes why the commands are pending or couldn't be run on the node.
+	Message string `json:"message,omitempty"`
+	// Results are the results of the commands run on the node, in order.
+	Results []CommandResult `json:"results,omitempty"`
+}
+
+// CommandResult is the result of a command.
+type CommandResult struct {
+	// Command is the command run.
+	Command string `json:"command"`
+	// Output is the output of the command, truncated.
+	Output string `json:"output,omitempty"`
+	// Error is the error of the failed command.
+	Error string `json:"error,omitempty"`
+}
+
+// GetTimeout returns the time a command is given to complete.
+func (s *SrlinuxCommandSpec) GetTimeout() time.Duration {
+	if s.TimeoutSeconds == 0 {
+		return defaultCommandTimeoutSeconds * time.Second
+	}
+
+	return time.Duration(s.TimeoutSeconds) * time.Second
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// SrlinuxCommand is the Schema for the srlinuxcommands API.
+// It runs CLI commands on the selected Srlinux nodes once they are ready and records their output.
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+type SrlinuxCommand struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SrlinuxCommandSpec   `json:"spec,omitempty"`
+	Status SrlinuxCommandStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SrlinuxCommandList contains a list of SrlinuxCommand.
+type SrlinuxCommandList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SrlinuxCommand `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SrlinuxCommand{}, &SrlinuxCommandList{})
+}
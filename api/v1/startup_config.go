@@ -0,0 +1,81 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// StartupConfigSpec defines the sources of the startup config.
+// The sources are fetched and merged in order by the controller, the merged config
+// is loaded on the node the same way as the config provided by kne.
+// Layered sources are not merged but loaded one after another.
+type StartupConfigSpec struct {
+	// Sources of the startup config, merged in order.
+	// JSON-styled sources are deep-merged with the later sources overriding the earlier ones;
+	// CLI-styled sources are concatenated. JSON- and CLI-styled sources can't be mixed.
+	Sources []StartupConfigSource `json:"sources,omitempty"`
+	// Layered loads the sources, including the config provided by kne, as separate fragments in order
+	// instead of merging them, so that JSON- and CLI-styled sources can be mixed, e.g. a common base
+	// config with node-specific overlays. JSON-styled fragments are loaded into the candidate with
+	// `load file` and CLI-styled fragments are sourced, followed by a single commit.
+	// When a fragment fails to load, the candidate is discarded, leaving the node config as it was before the load.
+	Layered bool `json:"layered,omitempty"`
+	// Template enables rendering of the sources, including the config provided by kne,
+	// as Go text/template templates before they are merged.
+	// Templates have access to .Name, .Namespace, .PodIP, .Interfaces, .Labels, .Annotations and .Values.
+	Template bool `json:"template,omitempty"`
+	// ValuesConfigMap is the name of a ConfigMap in the Srlinux namespace holding values shared
+	// by the nodes of the topology. Its keys are available to templates as .Values.
+	ValuesConfigMap string `json:"values-config-map,omitempty"`
+	// Values available to templates as .Values, overriding the values from ValuesConfigMap.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	Values *runtime.RawExtension `json:"values,omitempty"`
+}
+
+// StartupConfigSource is a source of the startup config. Exactly one of the fields is to be set.
+// The style of the config in ConfigMap and Secret keys and in URLs is defined by the extension,
+// .json, .yaml, .yml or .cli, or determined by the content when the extension is none of these.
+// YAML-styled config is converted to JSON.
+type StartupConfigSource struct {
+	// Inline is JSON-styled config written as YAML or JSON in the spec.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	Inline *runtime.RawExtension `json:"inline,omitempty"`
+	// InlineCLI is CLI-styled config written in the spec.
+	InlineCLI string `json:"inline-cli,omitempty"`
+	// ConfigMap selects keys of a ConfigMap in the Srlinux namespace.
+	ConfigMap *ConfigKeySelector `json:"config-map,omitempty"`
+	// Secret selects keys of a Secret in the Srlinux namespace, e.g. for configs containing credentials.
+	Secret *ConfigKeySelector `json:"secret,omitempty"`
+	// URL is an HTTP(S) URL the config is fetched from.
+	URL string `json:"url,omitempty"`
+}
+
+// ConfigKeySelector selects keys of a ConfigMap or a Secret.
+type ConfigKeySelector struct {
+	// Name of the ConfigMap or the Secret.
+	Name string `json:"name"`
+	// Keys to take the config from, merged in order.
+	// +kubebuilder:validation:MinItems=1
+	Keys []string `json:"keys"`
+}
+
+// HasStartupConfig returns true if the startup config is provided either by kne or by the startup config sources.
+func (s *SrlinuxSpec) HasStartupConfig() bool {
+	return s.StartupConfig != nil || s.GetConfig().ConfigDataPresent
+}
+
+// GetStartupConfig returns the startup config spec, or an empty spec when it is not set.
+func (s *SrlinuxSpec) GetStartupConfig() *StartupConfigSpec {
+	if s.StartupConfig == nil {
+		return &StartupConfigSpec{}
+	}
+
+	return s.StartupConfig
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Storage retention policies.
+const (
+	// StorageRetentionDelete deletes the claim created by the controller when the Srlinux is deleted.
+	StorageRetentionDelete = "Delete"
+	// StorageRetentionRetain keeps the claim created by the controller when the Srlinux is deleted.
+	// The claim is reused by the Srlinux re-created with the same name.
+	StorageRetentionRetain = "Retain"
+)
+
+const defaultStorageSize = "1Gi"
+
+// StorageSpec defines the persistent storage of the node state.
+// The storage keeps /etc/opt/srlinux (configs, checkpoints) and /var/log/srlinux across pod restarts.
+type StorageSpec struct {
+	// ClaimName is the name of an existing PersistentVolumeClaim in the Srlinux namespace to use.
+	// The claim is not managed by the controller.
+	ClaimName string `json:"claim-name,omitempty"`
+	// ClaimTemplate is the spec of the PersistentVolumeClaim the controller creates for the node
+	// when ClaimName is not set. Defaults to a 1Gi ReadWriteOnce claim of the default storage class.
+	ClaimTemplate *corev1.PersistentVolumeClaimSpec `json:"claim-template,omitempty"`
+	// RetentionPolicy defines what happens to the claim created by the controller when the Srlinux is deleted.
+	// Can be one of: "Delete" (default), "Retain".
+	// +kubebuilder:validation:Enum=Delete;Retain
+	RetentionPolicy string `json:"retention-policy,omitempty"`
+}
+
+// GetRetentionPolicy returns the retention policy of the claim, Delete is returned if not set.
+func (s *StorageSpec) GetRetentionPolicy() string {
+	if s.RetentionPolicy != "" {
+		return s.RetentionPolicy
+	}
+
+	return StorageRetentionDelete
+}
+
+// GetClaimTemplate returns the spec of the claim created for the node, default spec is returned if not set.
+func (s *StorageSpec) GetClaimTemplate() corev1.PersistentVolumeClaimSpec {
+	if s.ClaimTemplate != nil {
+		return *s.ClaimTemplate.DeepCopy()
+	}
+
+	return corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources: corev1.VolumeResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse(defaultStorageSize),
+			},
+		},
+	}
+}
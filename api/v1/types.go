@@ -4,10 +4,23 @@
 
 package v1
 
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 const (
 	defaultSrLinuxImageName          = "ghcr.io/nokia/srlinux"
 	defaultSrlinuxVariant            = "ixrd2l"
 	defaultSrlinuxInitContainerImage = "ghcr.io/srl-labs/init-wait:latest"
+	defaultImagePullPolicy           = corev1.PullIfNotPresent
+	defaultCertName                  = "kne-profile"
+	defaultCertKeySize               = 2048
+
+	// VersionDiscoveryDevice denotes version discovery by querying the booted SR Linux node.
+	VersionDiscoveryDevice = "device"
+	// VersionDiscoveryNone disables version discovery.
+	VersionDiscoveryNone = "none"
 )
 
 var (
@@ -45,6 +58,11 @@ type NodeConfig struct {
 	Image string `json:"image,omitempty"`
 	// Init container image to use with for the SR Linux container.
 	InitImage string `json:"init-image,omitempty"`
+	// Image pull policy for the SR Linux and init containers. Defaults to IfNotPresent.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"image-pull-policy,omitempty"`
+	// List of secrets in the Srlinux namespace used to pull the SR Linux and init container images.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"image-pull-secrets,omitempty"`
 	// Map of environment variables to pass into the pod.
 	Env map[string]string `json:"env,omitempty"`
 	// Specific entry point command for accessing the pod.
@@ -61,15 +79,34 @@ type NodeConfig struct {
 }
 
 // CertificateCfg represents srlinux certificate configuration parameters.
+// The controller generates the certificate and installs it on the node in the TLS server profile
+// named after the CertName, which is used by gNMI and JSON-RPC servers.
 type CertificateCfg struct {
-	// Certificate name on the node.
+	// Certificate name on the node. Used as the name of the TLS server profile.
 	CertName string `json:"cert_name,omitempty"`
-	// Key name on the node.
+	// Key name on the node. SR Linux stores the key in the TLS server profile, so the field is not used.
 	KeyName string `json:"key_name,omitempty"`
 	// RSA keysize to use for key generation.
 	KeySize uint32 `json:"key_size,omitempty"`
 	// Common name to set in the cert.
 	CommonName string `json:"common_name,omitempty"`
+	// CASecret is the name of the Secret in the Srlinux namespace holding the CA certificate (tls.crt)
+	// and key (tls.key) used to sign the node certificate. The certificate is self-signed if not set.
+	CASecret string `json:"ca_secret,omitempty"`
+}
+
+// CertificateStatus is the status of the node TLS certificate.
+type CertificateStatus struct {
+	// Secret is the name of the Secret holding the node certificate.
+	Secret string `json:"secret,omitempty"`
+	// Serial is the serial number of the issued certificate.
+	Serial string `json:"serial,omitempty"`
+	// Issuer is the subject of the certificate issuer.
+	Issuer string `json:"issuer,omitempty"`
+	// NotAfter is the time the certificate expires at.
+	NotAfter *metav1.Time `json:"not-after,omitempty"`
+	// InstalledSerial is the serial number of the certificate installed on the node.
+	InstalledSerial string `json:"installed-serial,omitempty"`
 }
 
 // GetCommand gets command from srlinux node configuration.
@@ -89,3 +126,40 @@ func (n *NodeConfig) GetArgs() []string {
 
 	return defaultArgs
 }
+
+// GetImagePullPolicy gets image pull policy from srlinux node configuration,
+// default pull policy is returned if none present.
+func (n *NodeConfig) GetImagePullPolicy() corev1.PullPolicy {
+	if n.ImagePullPolicy != "" {
+		return n.ImagePullPolicy
+	}
+
+	return defaultImagePullPolicy
+}
+
+// GetCertName gets the certificate name, default name is returned if not set.
+func (c *CertificateCfg) GetCertName() string {
+	if c.CertName != "" {
+		return c.CertName
+	}
+
+	return defaultCertName
+}
+
+// GetKeySize gets RSA key size, default key size is returned if not set.
+func (c *CertificateCfg) GetKeySize() uint32 {
+	if c.KeySize != 0 {
+		return c.KeySize
+	}
+
+	return defaultCertKeySize
+}
+
+// GetCommonName gets the common name of the certificate, node name is returned if not set.
+func (c *CertificateCfg) GetCommonName(nodeName string) string {
+	if c.CommonName != "" {
+		return c.CommonName
+	}
+
+	return nodeName
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package v1
+
+const (
+	// fixedBootSeconds is the time fixed form-factor variants take to boot.
+	fixedBootSeconds = 300
+	// chassisBootSeconds is the time chassis variants take to boot, as they run more line card apps.
+	chassisBootSeconds = 900
+)
+
+// variantProfile describes port capacity and boot characteristics of an SR Linux variant.
+type variantProfile struct {
+	// slots is the number of line card slots.
+	slots int
+	// ports is the number of front panel ports per line card.
+	ports int
+	// breakouts is the maximum number of breakout ports a port can be split into.
+	breakouts int
+	// bootSeconds is the time the variant takes to have its management server ready.
+	bootSeconds int32
+}
+
+// variantProfiles maps SR Linux variants (as defined in srlinux-variants config map)
+// to their profiles.
+//
+//nolint:gochecknoglobals,gomnd
+var variantProfiles = map[string]variantProfile{
+	"ixrd1":  {slots: 1, ports: 28, breakouts: 4, bootSeconds: fixedBootSeconds},
+	"ixrd2":  {slots: 1, ports: 58, breakouts: 4, bootSeconds: fixedBootSeconds},
+	"ixrd2l": {slots: 1, ports: 58, breakouts: 4, bootSeconds: fixedBootSeconds},
+	"ixrd3":  {slots: 1, ports: 34, breakouts: 4, bootSeconds: fixedBootSeconds},
+	"ixrd3l": {slots: 1, ports: 34, breakouts: 4, bootSeconds: fixedBootSeconds},
+	"ixrh2":  {slots: 1, ports: 128, breakouts: 4, bootSeconds: fixedBootSeconds},
+	"ixrh3":  {slots: 1, ports: 34, breakouts: 8, bootSeconds: fixedBootSeconds},
+	"ixr6":   {slots: 6, ports: 36, breakouts: 4, bootSeconds: chassisBootSeconds},
+	"ixr6e":  {slots: 6, ports: 36, breakouts: 8, bootSeconds: chassisBootSeconds},
+	"ixr10":  {slots: 10, ports: 36, breakouts: 4, bootSeconds: chassisBootSeconds},
+	"ixr10e": {slots: 10, ports: 36, breakouts: 8, bootSeconds: chassisBootSeconds},
+}
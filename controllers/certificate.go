@@ -0,0 +1,651 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/scrapli/scrapligo/driver/network"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	tlsVolName    = "tls"
+	tlsVolMntPath = "/tmp/tls"
+	// tlsCLIKey is the key of the TLS secret holding the CLI commands that install the certificate on the node.
+	tlsCLIKey = "tls.cli"
+	// tlsCAKey is the key of the TLS secret holding the certificate of the CA that signed the node certificate.
+	tlsCAKey = "ca.crt"
+
+	certValidity     = 365 * 24 * time.Hour
+	certSerialBits   = 128
+	certClockSkewGap = 5 * time.Minute
+	// certRenewalDivisor defines when the certificate is renewed: after (divisor-1)/divisor of its lifetime.
+	certRenewalDivisor = 3
+
+	// secretVolumeSyncDelay is the time kubelet takes to propagate an updated Secret to the mounted volume.
+	// It is based on the default kubelet sync period (1m) and the cache TTL of the Secret manager.
+	secretVolumeSyncDelay = 90 * time.Second
+	// certInstallRetryInterval is the interval a failed installation of the certificate on the node is retried at.
+	certInstallRetryInterval = 30 * time.Second
+
+	clusterDomain = "cluster.local"
+)
+
+var (
+	ErrInvalidCertificate = errors.New("invalid certificate")
+	ErrInvalidCA          = errors.New("invalid CA secret")
+)
+
+// certificateRequest holds the parameters of the node certificate.
+type certificateRequest struct {
+	commonName string
+	keySize    int
+	dnsNames   []string
+	ips        []net.IP
+}
+
+// certificateIssuer issues node certificates.
+// Self-signed and CA Secret issuers are provided; other issuers, e.g. the ones backed by cert-manager,
+// are plugged in by implementing this interface.
+type certificateIssuer interface {
+	// issue issues a certificate and a key for the request, both PEM-encoded.
+	issue(req *certificateRequest) (certPEM, keyPEM []byte, err error)
+	// issued reports whether the certificate was issued by this issuer.
+	issued(cert *x509.Certificate) bool
+	// caPEM returns the PEM-encoded CA certificate, nil is returned for self-signed certificates.
+	caPEM() []byte
+}
+
+// selfSignedIssuer issues self-signed certificates.
+type selfSignedIssuer struct{}
+
+func (selfSignedIssuer) issue(req *certificateRequest) (certPEM, keyPEM []byte, err error) {
+	return signCertificate(req, nil, nil)
+}
+
+func (selfSignedIssuer) issued(cert *x509.Certificate) bool {
+	// CheckSignatureFrom can't be used as the self-signed node certificate is not a CA
+	return cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+func (selfSignedIssuer) caPEM() []byte {
+	return nil
+}
+
+// caIssuer issues certificates signed by a CA stored in a Secret.
+type caIssuer struct {
+	cert    *x509.Certificate
+	key     crypto.Signer
+	certPEM []byte
+}
+
+func (c *caIssuer) issue(req *certificateRequest) (certPEM, keyPEM []byte, err error) {
+	return signCertificate(req, c.cert, c.key)
+}
+
+func (c *caIssuer) issued(cert *x509.Certificate) bool {
+	return cert.CheckSignatureFrom(c.cert) == nil
+}
+
+func (c *caIssuer) caPEM() []byte {
+	return c.certPEM
+}
+
+// newCAIssuer creates the CA issuer from PEM-encoded CA certificate and key.
+func newCAIssuer(certPEM, keyPEM []byte) (*caIssuer, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCA, err)
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCA, err)
+	}
+
+	if !cert.IsCA {
+		return nil, fmt.Errorf("%w: certificate %q is not a CA", ErrInvalidCA, cert.Subject.CommonName)
+	}
+
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidCA, pair.PrivateKey)
+	}
+
+	return &caIssuer{cert: cert, key: key, certPEM: certPEM}, nil
+}
+
+// certificateIssuerFor returns the issuer of the node certificate.
+// CA issuer is returned when the CA Secret is referenced in the certificate config.
+func (r *SrlinuxReconciler) certificateIssuerFor(
+	ctx context.Context,
+	s *srlinuxv1.Srlinux,
+) (certificateIssuer, error) {
+	caSecret := s.Spec.GetConfig().Cert.CASecret
+	if caSecret == "" {
+		return selfSignedIssuer{}, nil
+	}
+
+	secret := &corev1.Secret{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: caSecret, Namespace: s.Namespace}, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCAIssuer(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+}
+
+// tlsSecretName returns the name of the Secret holding the node TLS certificate.
+func tlsSecretName(s *srlinuxv1.Srlinux) string {
+	return fmt.Sprintf("%s-tls", s.Name)
+}
+
+// certificateRequestFor creates the certificate request for the node.
+// The certificate covers the node name, the management Service DNS names and the pod IP, if known.
+func certificateRequestFor(s *srlinuxv1.Srlinux, podIP string) *certificateRequest {
+	cfg := s.Spec.GetConfig().Cert
+
+	req := &certificateRequest{
+		commonName: cfg.GetCommonName(s.Name),
+		keySize:    int(cfg.GetKeySize()),
+		dnsNames:   []string{s.Name},
+	}
+
+	if s.Spec.Service != nil {
+		svc := serviceName(s)
+
+		req.dnsNames = append(req.dnsNames,
+			svc,
+			fmt.Sprintf("%s.%s", svc, s.Namespace),
+			fmt.Sprintf("%s.%s.svc", svc, s.Namespace),
+			fmt.Sprintf("%s.%s.svc.%s", svc, s.Namespace, clusterDomain),
+		)
+	}
+
+	if ip := net.ParseIP(podIP); ip != nil {
+		req.ips = append(req.ips, ip)
+	}
+
+	return req
+}
+
+// createTLSSecret issues the node certificate with the parameters defined in NodeConfig.Cert
+// and stores it in the <name>-tls Secret owned by the Srlinux object.
+// Along with the certificate and the key, the Secret contains the CLI commands that
+// install the certificate in the TLS server profile used by gNMI and JSON-RPC servers.
+// The existing Secret is left intact.
+func (r *SrlinuxReconciler) createTLSSecret(
+	ctx context.Context,
+	s *srlinuxv1.Srlinux,
+	log logr.Logger,
+) error {
+	if s.Spec.GetConfig().Cert == nil {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: tlsSecretName(s), Namespace: s.Namespace}, secret)
+	if err == nil || !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	issuer, err := r.certificateIssuerFor(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tlsSecretName(s),
+			Namespace: s.Namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+
+	if _, err := issueTLSSecretData(s, secret, issuer, certificateRequestFor(s, ""), log); err != nil {
+		return err
+	}
+
+	if err := ctrl.SetControllerReference(s, secret, r.Scheme); err != nil {
+		return err
+	}
+
+	log.Info("creating secret", "secret name", secret.Name)
+
+	return r.Create(ctx, secret)
+}
+
+// issueTLSSecretData issues the certificate and sets it, its key, the CA certificate
+// and the CLI commands installing them as the data of the TLS Secret.
+func issueTLSSecretData(
+	s *srlinuxv1.Srlinux,
+	secret *corev1.Secret,
+	issuer certificateIssuer,
+	req *certificateRequest,
+	log logr.Logger,
+) (*x509.Certificate, error) {
+	log.Info("issuing node certificate", "common name", req.commonName, "dns names", req.dnsNames, "ips", req.ips)
+
+	certPEM, keyPEM, err := issuer.issue(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cmds, err := createTLSCmds(s.Spec.GetConfig().Cert, certPEM, keyPEM, issuer.caPEM())
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+		tlsCLIKey:               cmds,
+	}
+
+	if ca := issuer.caPEM(); ca != nil {
+		secret.Data[tlsCAKey] = ca
+	}
+
+	return parseCertificate(certPEM)
+}
+
+// handleSrlinuxCertificate keeps the node certificate valid and installed on the node.
+// The certificate is re-issued when it approaches its expiry, when its SANs don't cover the pod IP
+// and Service DNS names, or when it was not issued by the configured issuer.
+// Re-issued certificates reach the pod via the mounted Secret and are installed once kubelet syncs the volume.
+// The returned duration is the time after which the certificate needs to be looked at again; 0 means never.
+func (r *SrlinuxReconciler) handleSrlinuxCertificate(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) time.Duration {
+	if srlinux.Spec.GetConfig().Cert == nil {
+		if srlinux.Status.Certificate != nil {
+			srlinux.Status.Certificate = nil
+			*update = true
+		}
+
+		return 0
+	}
+
+	secret := &corev1.Secret{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: tlsSecretName(srlinux), Namespace: srlinux.Namespace}, secret)
+	if err != nil {
+		// the secret is created along with the pod, as it is mounted to it
+		log.Error(err, "failed to get node certificate secret")
+
+		return 0
+	}
+
+	cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		log.Error(err, "failed to parse node certificate")
+
+		return 0
+	}
+
+	recordCertificateStatus(update, srlinux, secret, cert)
+
+	now := time.Now()
+
+	var requeue time.Duration
+
+	if srlinux.Status.Certificate.InstalledSerial != srlinux.Status.Certificate.Serial {
+		if wait := certificateMountWait(cert, pod, now); wait > 0 {
+			log.Info("waiting for the node certificate to be synced to the pod", "wait", wait)
+
+			requeue = wait
+		} else if r.installCertificate(ctx, log, srlinux, pod) {
+			srlinux.Status.Certificate.InstalledSerial = srlinux.Status.Certificate.Serial
+			*update = true
+		} else {
+			log.Info("node certificate is not installed, retrying", "retry after", certInstallRetryInterval)
+
+			requeue = certInstallRetryInterval
+		}
+	}
+
+	issuer, err := r.certificateIssuerFor(ctx, srlinux)
+	if err != nil {
+		log.Error(err, "failed to get node certificate issuer")
+
+		return secretVolumeSyncDelay
+	}
+
+	req := certificateRequestFor(srlinux, pod.Status.PodIP)
+
+	reason, renewAt := certificateRenewal(cert, req, issuer, now)
+	if reason == "" {
+		if requeue == 0 || renewAt.Sub(now) < requeue {
+			requeue = renewAt.Sub(now)
+		}
+
+		return requeue
+	}
+
+	log.Info("re-issuing node certificate", "reason", reason)
+
+	cert, err = issueTLSSecretData(srlinux, secret, issuer, req, log)
+	if err != nil {
+		log.Error(err, "failed to issue node certificate")
+
+		return secretVolumeSyncDelay
+	}
+
+	if err := r.Update(ctx, secret); err != nil {
+		log.Error(err, "failed to update node certificate secret")
+
+		return secretVolumeSyncDelay
+	}
+
+	recordCertificateStatus(update, srlinux, secret, cert)
+
+	return secretVolumeSyncDelay
+}
+
+// installCertificate installs the certificate mounted to the pod. Returns true on success.
+func (r *SrlinuxReconciler) installCertificate(
+	ctx context.Context,
+	log logr.Logger,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) bool {
+	driver := r.getNetworkDriver(ctx, log, pod)
+	if driver == nil {
+		return false
+	}
+
+	var err error
+
+	defer func() { r.releaseNetworkDriver(log, driver, err) }()
+
+	err = applyTLSConfig(ctx, driver, featuresFor(srlinux.GetVersion()), srlinux, log)
+	if err != nil {
+		log.Error(err, "failed to install node certificate")
+
+		return false
+	}
+
+	return true
+}
+
+// recordCertificateStatus records the issued certificate in the Srlinux status.
+func recordCertificateStatus(
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	secret *corev1.Secret,
+	cert *x509.Certificate,
+) {
+	if srlinux.Status.Certificate == nil {
+		srlinux.Status.Certificate = &srlinuxv1.CertificateStatus{}
+	}
+
+	st := srlinux.Status.Certificate
+	serial := cert.SerialNumber.Text(16) //nolint:mnd
+
+	if st.Serial == serial && st.Secret == secret.Name {
+		return
+	}
+
+	st.Secret = secret.Name
+	st.Serial = serial
+	st.Issuer = cert.Issuer.CommonName
+	st.NotAfter = &metav1.Time{Time: cert.NotAfter}
+	*update = true
+}
+
+// certificateMountWait returns the time left until the certificate reaches the volume mounted to the pod.
+// The certificate issued before the pod started is already there.
+func certificateMountWait(cert *x509.Certificate, pod *corev1.Pod, now time.Time) time.Duration {
+	issuedAt := cert.NotBefore.Add(certClockSkewGap)
+
+	if pod.Status.StartTime != nil && pod.Status.StartTime.After(issuedAt) {
+		return 0
+	}
+
+	return max(issuedAt.Add(secretVolumeSyncDelay).Sub(now), 0)
+}
+
+// certificateRenewal returns the reason the certificate needs to be re-issued, or an empty string
+// if it doesn't, along with the time the certificate is due for renewal.
+func certificateRenewal(
+	cert *x509.Certificate,
+	req *certificateRequest,
+	issuer certificateIssuer,
+	now time.Time,
+) (string, time.Time) {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	renewAt := cert.NotAfter.Add(-lifetime / certRenewalDivisor)
+
+	switch {
+	case !now.Before(renewAt):
+		return "certificate is due for renewal", renewAt
+	case !issuer.issued(cert):
+		return "certificate issuer changed", renewAt
+	case cert.Subject.CommonName != req.commonName:
+		return "common name changed", renewAt
+	case !coversSANs(cert, req):
+		return "SANs changed", renewAt
+	}
+
+	if key, ok := cert.PublicKey.(*rsa.PublicKey); ok && key.N.BitLen() != req.keySize {
+		return "key size changed", renewAt
+	}
+
+	return "", renewAt
+}
+
+// coversSANs reports whether the certificate covers all DNS names and IPs of the request.
+func coversSANs(cert *x509.Certificate, req *certificateRequest) bool {
+	for _, name := range req.dnsNames {
+		if !slices.Contains(cert.DNSNames, name) {
+			return false
+		}
+	}
+
+	for _, ip := range req.ips {
+		if !slices.ContainsFunc(cert.IPAddresses, ip.Equal) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseCertificate parses PEM-encoded certificate.
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM data found", ErrInvalidCertificate)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// signCertificate generates an RSA key of the requested size and a certificate for it.
+// The certificate is signed by the parent certificate and key, or is self-signed if parent is nil.
+func signCertificate(
+	req *certificateRequest,
+	parent *x509.Certificate,
+	parentKey crypto.Signer,
+) (certPEM, keyPEM []byte, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, req.keySize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), certSerialBits))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName: req.commonName,
+		},
+		DNSNames:              req.dnsNames,
+		IPAddresses:           req.ips,
+		NotBefore:             now.Add(-certClockSkewGap),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	} else if parent.NotAfter.Before(tmpl.NotAfter) {
+		// the certificate can't outlive its CA
+		tmpl.NotAfter = parent.NotAfter
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return certPEM, keyPEM, nil
+}
+
+// createTLSCmds creates the CLI commands that install the certificate in the TLS server profile
+// and make gNMI and JSON-RPC servers in mgmt network instance use this profile.
+// The CA certificate, if present, is set as the trust anchor of the profile.
+// The PEM material is passed as multi-line quoted CLI values, see cliMultilineValue.
+func createTLSCmds(cfg *srlinuxv1.CertificateCfg, certPEM, keyPEM, caPEM []byte) ([]byte, error) {
+	profile := cfg.GetCertName()
+
+	type pemValue struct {
+		leaf string
+		pem  []byte
+	}
+
+	values := []pemValue{{"key", keyPEM}, {"certificate", certPEM}}
+
+	if caPEM != nil {
+		values = append(values, pemValue{"trust-anchor", caPEM})
+	}
+
+	cmds := make([]string, 0, len(values))
+
+	for _, v := range values {
+		q, err := cliMultilineValue(strings.TrimSpace(string(v.pem)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidCertificate, v.leaf, err)
+		}
+
+		cmds = append(cmds, fmt.Sprintf("set / system tls server-profile %s %s %s", profile, v.leaf, q))
+	}
+
+	cmds = append(cmds,
+		fmt.Sprintf("set / system tls server-profile %s authenticate-client false", profile),
+		"set / system gnmi-server admin-state enable",
+		"set / system gnmi-server network-instance mgmt admin-state enable",
+		fmt.Sprintf("set / system gnmi-server network-instance mgmt tls-profile %s", profile),
+		"set / system json-rpc-server admin-state enable",
+		"set / system json-rpc-server network-instance mgmt https admin-state enable",
+		fmt.Sprintf("set / system json-rpc-server network-instance mgmt https tls-profile %s", profile),
+	)
+
+	return []byte(strings.Join(cmds, "\n") + "\n"), nil
+}
+
+// cliMultilineValue quotes a value spanning several lines for the SR Linux CLI.
+// A double-quoted CLI value continues until the closing quote, including newlines,
+// so the value is written as is. Backslashes and double quotes are special inside the quotes
+// and are never part of PEM material, hence values containing them are refused instead of escaped.
+func cliMultilineValue(v string) (string, error) {
+	if strings.ContainsAny(v, `"\`) {
+		return "", fmt.Errorf("value contains characters special to the CLI quoting: %q", v) //nolint:goerr113
+	}
+
+	return `"` + v + `"`, nil
+}
+
+// applyTLSConfig installs the node certificate mounted from the TLS Secret
+// and saves the configuration.
+func applyTLSConfig(
+	_ context.Context,
+	d *network.Driver,
+	f *srlFeatures,
+	s *srlinuxv1.Srlinux,
+	log logr.Logger,
+) error {
+	if s.Spec.GetConfig().Cert == nil {
+		return nil
+	}
+
+	log.Info("Installing node certificate...", "tls profile", s.Spec.GetConfig().Cert.GetCertName())
+
+	r, err := d.SendConfigs([]string{
+		fmt.Sprintf(f.loadCLICmd, tlsVolMntPath+"/"+tlsCLIKey),
+		f.saveCmd,
+	})
+	if err != nil {
+		log.Error(err, "failed to send commands")
+
+		return err
+	}
+
+	if r.Failed != nil {
+		log.Error(r.Failed, "applying commands failed")
+
+		return r.Failed
+	}
+
+	return nil
+}
+
+// createTLSVolume creates a volume with the node certificate.
+func createTLSVolume(s *srlinuxv1.Srlinux) corev1.Volume {
+	return corev1.Volume{
+		Name: tlsVolName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: tlsSecretName(s),
+			},
+		},
+	}
+}
+
+// createTLSVolumeMount creates a volume mount for the node certificate.
+func createTLSVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      tlsVolName,
+		MountPath: tlsVolMntPath,
+		ReadOnly:  true,
+	}
+}
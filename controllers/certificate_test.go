@@ -0,0 +1,379 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/scrapli/scrapligo/driver/network"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestCA creates a PEM-encoded CA certificate and key.
+func newTestCA(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "lab-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(2 * certValidity),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestCertificateRequestFor(t *testing.T) {
+	srl := &srlinuxv1.Srlinux{
+		ObjectMeta: metav1.ObjectMeta{Name: "srl1", Namespace: "lab"},
+		Spec: srlinuxv1.SrlinuxSpec{
+			Config:  &srlinuxv1.NodeConfig{Cert: &srlinuxv1.CertificateCfg{}},
+			Service: &srlinuxv1.ServiceSpec{},
+		},
+	}
+
+	req := certificateRequestFor(srl, "10.0.0.1")
+
+	want := []string{"srl1", "srl1-mgmt", "srl1-mgmt.lab", "srl1-mgmt.lab.svc", "srl1-mgmt.lab.svc.cluster.local"}
+	if strings.Join(req.dnsNames, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected dns names\nactual: %v\nexpected: %v", req.dnsNames, want)
+	}
+
+	if len(req.ips) != 1 || !req.ips[0].Equal(net.ParseIP("10.0.0.1")) {
+		t.Fatalf("unexpected ips: %v", req.ips)
+	}
+
+	if req.commonName != "srl1" || req.keySize != 2048 {
+		t.Fatalf("unexpected defaults: common name %q, key size %d", req.commonName, req.keySize)
+	}
+}
+
+func TestCertificateIssuers(t *testing.T) {
+	caCert, caKey := newTestCA(t)
+
+	ca, err := newCAIssuer(caCert, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA issuer: %v", err)
+	}
+
+	req := &certificateRequest{
+		commonName: "r1.lab",
+		keySize:    1024,
+		dnsNames:   []string{"srl1"},
+		ips:        []net.IP{net.ParseIP("10.0.0.1")},
+	}
+
+	tests := []struct {
+		desc       string
+		issuer     certificateIssuer
+		wantIssuer string
+	}{
+		{
+			desc:       "self-signed",
+			issuer:     selfSignedIssuer{},
+			wantIssuer: "r1.lab",
+		},
+		{
+			desc:       "signed by CA",
+			issuer:     ca,
+			wantIssuer: "lab-ca",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			certPEM, keyPEM, err := tt.issuer.issue(req)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			cert, err := parseCertificate(certPEM)
+			if err != nil {
+				t.Fatalf("%s: failed to parse certificate: %v", tt.desc, err)
+			}
+
+			if cert.Issuer.CommonName != tt.wantIssuer || !tt.issuer.issued(cert) {
+				t.Fatalf("%s: expected certificate issued by %q, got %q", tt.desc, tt.wantIssuer, cert.Issuer.CommonName)
+			}
+
+			keyBlock, _ := pem.Decode(keyPEM)
+			if keyBlock == nil {
+				t.Fatalf("%s: key is not PEM encoded", tt.desc)
+			}
+
+			if _, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes); err != nil {
+				t.Fatalf("%s: failed to parse key: %v", tt.desc, err)
+			}
+
+			if reason, _ := certificateRenewal(cert, req, tt.issuer, time.Now()); reason != "" {
+				t.Fatalf("%s: freshly issued certificate needs renewal: %s", tt.desc, reason)
+			}
+
+			cmdsData, err := createTLSCmds(&srlinuxv1.CertificateCfg{}, certPEM, keyPEM, tt.issuer.caPEM())
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			cmds := string(cmdsData)
+			if !strings.Contains(cmds, "gnmi-server network-instance mgmt tls-profile kne-profile") {
+				t.Fatalf("%s: gNMI server is not wired to the TLS profile:\n%s", tt.desc, cmds)
+			}
+
+			if hasTrustAnchor := strings.Contains(cmds, "trust-anchor"); hasTrustAnchor != (tt.issuer.caPEM() != nil) {
+				t.Fatalf("%s: unexpected trust anchor presence in commands:\n%s", tt.desc, cmds)
+			}
+		},
+		)
+	}
+}
+
+func TestCertificateRenewal(t *testing.T) {
+	caCert, caKey := newTestCA(t)
+
+	ca, err := newCAIssuer(caCert, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA issuer: %v", err)
+	}
+
+	req := &certificateRequest{commonName: "srl1", keySize: 1024, dnsNames: []string{"srl1"}}
+
+	certPEM, _, err := selfSignedIssuer{}.issue(req)
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	tests := []struct {
+		desc   string
+		req    *certificateRequest
+		issuer certificateIssuer
+		now    time.Time
+		want   string
+	}{
+		{
+			desc:   "valid certificate",
+			req:    req,
+			issuer: selfSignedIssuer{},
+			now:    time.Now(),
+			want:   "",
+		},
+		{
+			desc:   "pod IP not covered",
+			req:    &certificateRequest{commonName: "srl1", keySize: 1024, ips: []net.IP{net.ParseIP("10.0.0.1")}},
+			issuer: selfSignedIssuer{},
+			now:    time.Now(),
+			want:   "SANs changed",
+		},
+		{
+			desc:   "issuer changed to CA",
+			req:    req,
+			issuer: ca,
+			now:    time.Now(),
+			want:   "certificate issuer changed",
+		},
+		{
+			desc:   "key size changed",
+			req:    &certificateRequest{commonName: "srl1", keySize: 2048},
+			issuer: selfSignedIssuer{},
+			now:    time.Now(),
+			want:   "key size changed",
+		},
+		{
+			desc:   "approaching expiry",
+			req:    req,
+			issuer: selfSignedIssuer{},
+			now:    cert.NotAfter.Add(-time.Hour),
+			want:   "certificate is due for renewal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			reason, _ := certificateRenewal(cert, tt.req, tt.issuer, tt.now)
+			if reason != tt.want {
+				t.Fatalf("%s: expected renewal reason %q, got %q", tt.desc, tt.want, reason)
+			}
+		},
+		)
+	}
+}
+
+func TestHandleSrlinuxCertificateInstallRetry(t *testing.T) {
+	p, f := newFakeSessionPool()
+	p.open = func(logr.Logger, string) *network.Driver {
+		f.opened++
+
+		return nil
+	}
+
+	r := &SrlinuxReconciler{
+		Client:   fake.NewClientBuilder().Build(),
+		Scheme:   scheme.Scheme,
+		Sessions: p,
+	}
+
+	srl := &srlinuxv1.Srlinux{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultCRName, Namespace: defaultNamespace},
+		Spec: srlinuxv1.SrlinuxSpec{
+			Config: &srlinuxv1.NodeConfig{Cert: &srlinuxv1.CertificateCfg{}},
+		},
+	}
+
+	if err := r.createTLSSecret(ctx, srl, ctrl.Log); err != nil {
+		t.Fatalf("failed to create node certificate secret: %v", err)
+	}
+
+	// the pod started after the certificate was issued, the certificate is in the mounted volume
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultCRName, Namespace: defaultNamespace, UID: "uid1"},
+		Status:     corev1.PodStatus{StartTime: &metav1.Time{Time: time.Now().Add(time.Hour)}},
+	}
+
+	update := false
+
+	requeue := r.handleSrlinuxCertificate(ctx, ctrl.Log, &update, srl, pod)
+
+	if requeue != certInstallRetryInterval || f.opened != 1 || srl.Status.Certificate.InstalledSerial != "" {
+		t.Fatalf("expected the failed install to be retried after %v, got requeue %v, opened %d sessions",
+			certInstallRetryInterval, requeue, f.opened)
+	}
+}
+
+func TestNewCAIssuerRejectsLeaf(t *testing.T) {
+	certPEM, keyPEM, err := selfSignedIssuer{}.issue(&certificateRequest{commonName: "srl1", keySize: 1024})
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+
+	if _, err := newCAIssuer(certPEM, keyPEM); err == nil {
+		t.Fatal("expected error for non-CA certificate")
+	}
+}
+
+// splitCLIWords splits the CLI commands into words following the SR Linux CLI quoting rules:
+// words are separated by whitespace, a command ends at a newline outside of quotes,
+// double-quoted words may span several lines and a backslash escapes the next character.
+func splitCLIWords(t *testing.T, cmds string) [][]string {
+	t.Helper()
+
+	var (
+		lines  [][]string
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quoted bool
+	)
+
+	for i := 0; i < len(cmds); i++ {
+		c := cmds[i]
+
+		switch {
+		case c == '\\' && i+1 < len(cmds):
+			i++
+			word.WriteByte(cmds[i])
+			inWord = true
+		case c == '"':
+			quoted = !quoted
+			inWord = true
+		case quoted:
+			word.WriteByte(c)
+		case c == ' ' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+			if c == '\n' && len(words) > 0 {
+				lines = append(lines, words)
+				words = nil
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if quoted {
+		t.Fatalf("unterminated quoted value in commands:\n%s", cmds)
+	}
+
+	return lines
+}
+
+func TestCreateTLSCmdsQuoting(t *testing.T) {
+	certPEM, keyPEM, err := selfSignedIssuer{}.issue(&certificateRequest{commonName: "srl1", keySize: 1024})
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+
+	cmds, err := createTLSCmds(&srlinuxv1.CertificateCfg{}, certPEM, keyPEM, certPEM)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(string(cmds), `\n`) {
+		t.Fatalf("PEM newlines are escaped in commands:\n%s", cmds)
+	}
+
+	want := map[string]string{
+		"key":          strings.TrimSpace(string(keyPEM)),
+		"certificate":  strings.TrimSpace(string(certPEM)),
+		"trust-anchor": strings.TrimSpace(string(certPEM)),
+	}
+
+	got := map[string]string{}
+
+	for _, words := range splitCLIWords(t, string(cmds)) {
+		// set / system tls server-profile <profile> <leaf> <value>
+		if len(words) == 8 && words[4] == "server-profile" && want[words[6]] != "" {
+			got[words[6]] = words[7]
+		}
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("PEM values parsed from the commands do not match:\n%s", cmp.Diff(want, got))
+	}
+
+	_, err = createTLSCmds(&srlinuxv1.CertificateCfg{}, []byte(`"injected`), keyPEM, nil)
+	if !errors.Is(err, ErrInvalidCertificate) {
+		t.Fatalf("expected invalid certificate error for a value with a quote, got: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ package controllers
 
 import (
 	"context"
+	"maps"
 
 	"github.com/go-logr/logr"
 	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
@@ -117,40 +118,50 @@ func createTopomacScriptCfgMap(
 	return err
 }
 
+// createKNEEntrypointCfgMap creates the kne-entrypoint config map, or updates it when its content differs
+// from the one shipped with the controller, e.g. when the namespace was set up by an older controller
+// which entrypoint doesn't handle persistent storage.
 func createKNEEntrypointCfgMap(
 	ctx context.Context,
 	r *SrlinuxReconciler,
 	ns string,
 	log logr.Logger,
 ) error {
-	// Check if the kne-entrypoint cfg map already exists, if not create a new one
-	cfgMap := &corev1.ConfigMap{}
+	data, err := variantsFS.ReadFile("manifests/variants/kne-entrypoint.yml")
+	if err != nil {
+		return err
+	}
 
-	err := r.Get(ctx, types.NamespacedName{Name: entrypointCfgMapName, Namespace: ns}, cfgMap)
-	if err != nil && errors.IsNotFound(err) {
-		log.Info("creating a new kne-entrypoint configmap")
+	desired := &corev1.ConfigMap{}
+	decoder := serializer.NewCodecFactory(clientgoscheme.Scheme).UniversalDecoder()
 
-		data, err := variantsFS.ReadFile("manifests/variants/kne-entrypoint.yml")
-		if err != nil {
-			return err
-		}
+	err = runtime.DecodeInto(decoder, data, desired)
+	if err != nil {
+		return err
+	}
 
-		decoder := serializer.NewCodecFactory(clientgoscheme.Scheme).UniversalDecoder()
+	desired.Namespace = ns
 
-		err = runtime.DecodeInto(decoder, data, cfgMap)
-		if err != nil {
-			return err
-		}
+	cfgMap := &corev1.ConfigMap{}
 
-		cfgMap.Namespace = ns
+	err = r.Get(ctx, types.NamespacedName{Name: entrypointCfgMapName, Namespace: ns}, cfgMap)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("creating a new kne-entrypoint configmap")
 
-		err = r.Create(ctx, cfgMap)
-		if err != nil {
-			return err
-		}
+		return r.Create(ctx, desired)
+	}
+
+	if err != nil {
+		return err
+	}
 
+	if maps.Equal(cfgMap.Data, desired.Data) {
 		return nil
 	}
 
-	return err
+	log.Info("updating the kne-entrypoint configmap")
+
+	cfgMap.Data = desired.Data
+
+	return r.Update(ctx, cfgMap)
 }
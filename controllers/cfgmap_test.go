@@ -0,0 +1,61 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateKNEEntrypointCfgMap(t *testing.T) {
+	tests := []struct {
+		desc     string
+		existing []runtime.Object
+	}{
+		{
+			desc: "config map created",
+		},
+		{
+			desc: "outdated config map updated",
+			existing: []runtime.Object{&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: entrypointCfgMapName, Namespace: defaultNamespace},
+				Data:       map[string]string{"kne-entrypoint.sh": "#!/bin/bash\n"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &SrlinuxReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(tt.existing...).Build(),
+				Scheme: scheme.Scheme,
+			}
+
+			if err := createKNEEntrypointCfgMap(ctx, r, defaultNamespace, ctrl.Log); err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			cfgMap := &corev1.ConfigMap{}
+			if err := r.Get(ctx, types.NamespacedName{Name: entrypointCfgMapName, Namespace: defaultNamespace}, cfgMap); err != nil {
+				t.Fatalf("%s: failed to get the config map: %v", tt.desc, err)
+			}
+
+			got := cfgMap.Data["kne-entrypoint.sh"]
+			if !strings.Contains(got, persistentStorageEnv) {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc, got, "entrypoint handling "+persistentStorageEnv)
+			}
+		},
+		)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/scrapli/scrapligo/driver/network"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// runningConfigMapName returns the name of the ConfigMap the running config of the node is exported to.
+func runningConfigMapName(s *srlinuxv1.Srlinux) string {
+	return fmt.Sprintf("%s-running-config", s.Name)
+}
+
+// configExportRetryInterval is the interval a failed export of the running config is retried at.
+const configExportRetryInterval = 30 * time.Second
+
+var ErrConfigMapNotControlled = errors.New("config map exists and is not controlled by the Srlinux")
+
+// handleSrlinuxConfigExport exports the running config of the node to the <name>-running-config ConfigMap
+// when the export annotation is set to a value the export has not been made for yet.
+// The request is recorded in the status once the export succeeds; a failed export is retried
+// after configExportRetryInterval, which is returned.
+func (r *SrlinuxReconciler) handleSrlinuxConfigExport(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) time.Duration {
+	req, ok := srlinux.Annotations[srlinuxv1.ExportConfigAnnotation]
+	if !ok || (srlinux.Status.ConfigExport != nil && srlinux.Status.ConfigExport.Request == req) {
+		return 0
+	}
+
+	log.Info("exporting running config", "request", req, "config map", runningConfigMapName(srlinux))
+
+	if srlinux.Status.ConfigExport == nil {
+		srlinux.Status.ConfigExport = &srlinuxv1.ConfigExportStatus{}
+	}
+
+	st := srlinux.Status.ConfigExport
+
+	if err := r.exportRunningConfig(ctx, log, srlinux, pod); err != nil {
+		log.Error(err, "failed to export running config, retrying", "retry after", configExportRetryInterval)
+
+		if st.Message != err.Error() {
+			st.Message = err.Error()
+			*update = true
+		}
+
+		return configExportRetryInterval
+	}
+
+	st.Request = req
+	st.ConfigMap = runningConfigMapName(srlinux)
+	st.Time = &metav1.Time{Time: time.Now()}
+	st.Message = ""
+	*update = true
+
+	return 0
+}
+
+// exportRunningConfig fetches the running config of the node and writes it to the running config ConfigMap.
+func (r *SrlinuxReconciler) exportRunningConfig(
+	ctx context.Context,
+	log logr.Logger,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) error {
+	driver := r.getNetworkDriver(ctx, log, pod)
+	if driver == nil {
+		return ErrNetworkNotReady
+	}
+
+	data, err := fetchRunningConfig(driver, featuresFor(srlinux.GetVersion()))
+
+	r.releaseNetworkDriver(log, driver, err)
+
+	if err != nil {
+		return err
+	}
+
+	return r.writeRunningConfigMap(ctx, log, srlinux, data)
+}
+
+// fetchRunningConfig retrieves the running config in the JSON and the CLI flat formats,
+// keyed by the names of the config files.
+func fetchRunningConfig(d *network.Driver, f *srlFeatures) (map[string]string, error) {
+	data := map[string]string{}
+
+	for file, cmd := range map[string]string{
+		"config.json": f.runningJSONCmd,
+		"config.cli":  f.runningFlatCmd,
+	} {
+		r, err := d.SendCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Failed != nil {
+			return nil, r.Failed
+		}
+
+		data[file] = strings.TrimSpace(r.Result) + "\n"
+	}
+
+	return data, nil
+}
+
+// writeRunningConfigMap creates or updates the ConfigMap holding the exported running config.
+// The ConfigMap is owned by the Srlinux object; an existing ConfigMap not controlled by it is not overwritten.
+func (r *SrlinuxReconciler) writeRunningConfigMap(
+	ctx context.Context,
+	log logr.Logger,
+	s *srlinuxv1.Srlinux,
+	data map[string]string,
+) error {
+	cm := &corev1.ConfigMap{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: runningConfigMapName(s), Namespace: s.Namespace}, cm)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	if k8serrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      runningConfigMapName(s),
+				Namespace: s.Namespace,
+			},
+			Data: data,
+		}
+
+		if err := ctrl.SetControllerReference(s, cm, r.Scheme); err != nil {
+			return err
+		}
+
+		log.Info("creating config map", "config map name", cm.Name)
+
+		return r.Create(ctx, cm)
+	}
+
+	if !metav1.IsControlledBy(cm, s) {
+		return fmt.Errorf("%w: %s", ErrConfigMapNotControlled, cm.Name)
+	}
+
+	log.Info("updating config map", "config map name", cm.Name)
+
+	cm.Data = data
+
+	return r.Update(ctx, cm)
+}
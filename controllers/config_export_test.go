@@ -0,0 +1,172 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/scrapli/scrapligo/driver/network"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWriteRunningConfigMap(t *testing.T) {
+	r := &SrlinuxReconciler{
+		Client: fake.NewClientBuilder().Build(),
+		Scheme: scheme.Scheme,
+	}
+
+	srl := &srlinuxv1.Srlinux{ObjectMeta: metav1.ObjectMeta{Name: defaultCRName, Namespace: defaultNamespace}}
+
+	for _, data := range []map[string]string{
+		{"config.json": "{}\n", "config.cli": "set / system name host-name r1\n"},
+		{"config.json": "{\"system\": {}}\n", "config.cli": "set / system name host-name r2\n"},
+	} {
+		if err := r.writeRunningConfigMap(ctx, ctrl.Log, srl, data); err != nil {
+			t.Fatalf("failed to write running config map: %v", err)
+		}
+
+		cm := &corev1.ConfigMap{}
+
+		err := r.Get(ctx, types.NamespacedName{Name: defaultCRName + "-running-config", Namespace: defaultNamespace}, cm)
+		if err != nil {
+			t.Fatalf("failed to get running config map: %v", err)
+		}
+
+		if !cmp.Equal(cm.Data, data) || len(cm.OwnerReferences) != 1 {
+			t.Fatalf("actual and expected inputs do not match\nactual: %+v\nexpected:%+v", cm.Data, data)
+		}
+	}
+}
+
+func TestWriteRunningConfigMapNotControlled(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultCRName + "-running-config", Namespace: defaultNamespace},
+		Data:       map[string]string{"config.json": "{}\n"},
+	}
+
+	r := &SrlinuxReconciler{
+		Client: fake.NewClientBuilder().WithRuntimeObjects(cm).Build(),
+		Scheme: scheme.Scheme,
+	}
+
+	srl := &srlinuxv1.Srlinux{ObjectMeta: metav1.ObjectMeta{Name: defaultCRName, Namespace: defaultNamespace}}
+
+	err := r.writeRunningConfigMap(ctx, ctrl.Log, srl, map[string]string{"config.json": "{\"system\": {}}\n"})
+	if !errors.Is(err, ErrConfigMapNotControlled) {
+		t.Fatalf("expected the config map not controlled by the Srlinux to be refused, got: %v", err)
+	}
+
+	got := &corev1.ConfigMap{}
+
+	if err := r.Get(ctx, types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, got); err != nil {
+		t.Fatalf("failed to get running config map: %v", err)
+	}
+
+	if !cmp.Equal(got.Data, cm.Data) {
+		t.Fatalf("actual and expected inputs do not match\nactual: %+v\nexpected:%+v", got.Data, cm.Data)
+	}
+}
+
+func TestHandleSrlinuxConfigExportFailure(t *testing.T) {
+	p, f := newFakeSessionPool()
+	p.open = func(logr.Logger, string) *network.Driver {
+		f.opened++
+
+		return nil
+	}
+
+	r := &SrlinuxReconciler{
+		Client:   fake.NewClientBuilder().Build(),
+		Scheme:   scheme.Scheme,
+		Sessions: p,
+	}
+
+	exported := &metav1.Time{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	srl := &srlinuxv1.Srlinux{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        defaultCRName,
+			Namespace:   defaultNamespace,
+			Annotations: map[string]string{srlinuxv1.ExportConfigAnnotation: "2"},
+		},
+		Status: srlinuxv1.SrlinuxStatus{
+			ConfigExport: &srlinuxv1.ConfigExportStatus{
+				Request:   "1",
+				ConfigMap: defaultCRName + "-running-config",
+				Time:      exported,
+			},
+		},
+	}
+
+	want := &srlinuxv1.ConfigExportStatus{
+		Request:   "1",
+		ConfigMap: defaultCRName + "-running-config",
+		Time:      exported,
+		Message:   ErrNetworkNotReady.Error(),
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultCRName, Namespace: defaultNamespace, UID: "uid1"},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+	}
+
+	for i, wantUpdate := range []bool{true, false} {
+		update := false
+
+		requeue := r.handleSrlinuxConfigExport(ctx, ctrl.Log, &update, srl, pod)
+
+		if update != wantUpdate || requeue != configExportRetryInterval || f.opened != i+1 {
+			t.Fatalf("attempt %d: unexpected status update %v or requeue %v", i+1, update, requeue)
+		}
+
+		if !cmp.Equal(srl.Status.ConfigExport, want) {
+			t.Fatalf("actual and expected inputs do not match\nactual: %+v\nexpected:%+v", srl.Status.ConfigExport, want)
+		}
+	}
+}
+
+func TestHandleSrlinuxConfigExportSkipsHandledRequest(t *testing.T) {
+	tests := []struct {
+		desc        string
+		annotations map[string]string
+		status      *srlinuxv1.ConfigExportStatus
+	}{
+		{
+			desc: "no export requested",
+		},
+		{
+			desc:        "export already made for the request",
+			annotations: map[string]string{srlinuxv1.ExportConfigAnnotation: "1"},
+			status:      &srlinuxv1.ConfigExportStatus{Request: "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			update := false
+			srl := &srlinuxv1.Srlinux{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Status:     srlinuxv1.SrlinuxStatus{ConfigExport: tt.status},
+			}
+
+			requeue := (&SrlinuxReconciler{}).handleSrlinuxConfigExport(ctx, ctrl.Log, &update, srl, &corev1.Pod{})
+
+			if update || requeue != 0 {
+				t.Fatalf("%s: unexpected status update %v or requeue %v", tt.desc, update, requeue)
+			}
+		},
+		)
+	}
+}
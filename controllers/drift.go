@@ -0,0 +1,317 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/scrapli/scrapligo/driver/network"
+	"github.com/scrapli/scrapligo/driver/opoptions"
+	"github.com/scrapli/scrapligo/response"
+	"github.com/scrapli/scrapligo/util"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// driftRetryInterval is the interval the drift check is retried at when the node can't be reached.
+	driftRetryInterval = 30 * time.Second
+	// driftDiffMaxLines limits the number of differing config lines kept in the status.
+	driftDiffMaxLines = 20
+)
+
+// configDiff is the difference between the running config and the startup config in the flat format.
+type configDiff struct {
+	added   []string
+	removed []string
+}
+
+// handleSrlinuxDrift periodically checks if the node config drifted from the startup config
+// the node was provisioned with, kept in the initial checkpoint, and records the result.
+// When remediation is enabled, the drifted config is reverted to the initial checkpoint.
+// The returned duration is the time after which the drift is to be checked again; 0 means never.
+func (r *SrlinuxReconciler) handleSrlinuxDrift(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) time.Duration {
+	spec := srlinux.Spec.Drift
+	if spec == nil {
+		if srlinux.Status.Drift != nil {
+			srlinux.Status.Drift = nil
+			meta.RemoveStatusCondition(&srlinux.Status.Conditions, srlinuxv1.ConditionDrifted)
+			*update = true
+		}
+
+		return 0
+	}
+
+	// the initial checkpoint exists once the startup config is processed
+	switch srlinux.Status.StartupConfig.Phase {
+	case "loaded", "not-provided", "persisted":
+	default:
+		return 0
+	}
+
+	now := time.Now()
+
+	st := srlinux.Status.Drift
+	if st != nil && st.LastCheckTime != nil {
+		if wait := st.LastCheckTime.Add(spec.GetInterval()).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	// the result of the previous check is kept when the drift can't be checked
+	if st == nil {
+		st = &srlinuxv1.DriftStatus{}
+	}
+
+	st.LastCheckTime = &metav1.Time{Time: now}
+	st.Message = ""
+	srlinux.Status.Drift = st
+	*update = true
+
+	driver := r.getNetworkDriver(ctx, log, pod)
+	if driver == nil {
+		st.Message = "management session is not established"
+
+		return driftRetryInterval
+	}
+
+	var err error
+
+	defer func() { r.releaseNetworkDriver(log, driver, err) }()
+
+	f := featuresFor(srlinux.GetVersion())
+
+	diff, err := checkConfigDrift(driver, f, driftIgnorePaths(srlinux))
+	if err != nil {
+		log.Error(err, "failed to check config drift")
+
+		st.Message = err.Error()
+
+		return driftRetryInterval
+	}
+
+	recordConfigDrift(st, diff)
+
+	if st.Drifted && spec.Remediate {
+		log.Info("config drifted from the startup config, reverting", "added", st.Added, "removed", st.Removed)
+
+		if err = revertToInitCheckpoint(driver, f, log); err != nil {
+			log.Error(err, "failed to revert config drift")
+
+			st.Message = fmt.Sprintf("remediation failed: %v", err)
+		} else {
+			st.LastRemediationTime = &metav1.Time{Time: now}
+
+			// the certificate installed after the checkpoint is reverted with the drift
+			if srlinux.Status.Certificate != nil {
+				srlinux.Status.Certificate.InstalledSerial = ""
+			}
+		}
+	}
+
+	c := driftCondition(st, spec.Remediate)
+	c.ObservedGeneration = srlinux.Generation
+	meta.SetStatusCondition(&srlinux.Status.Conditions, c)
+
+	return spec.GetInterval()
+}
+
+// driftIgnorePaths returns the config paths not considered a drift: the paths set in the spec
+// and the paths of the config the controller applies after the initial checkpoint.
+func driftIgnorePaths(s *srlinuxv1.Srlinux) []string {
+	paths := slices.Clone(s.Spec.Drift.IgnorePaths)
+
+	if cert := s.Spec.GetConfig().Cert; cert != nil {
+		paths = append(paths,
+			"/ system tls server-profile "+cert.GetCertName(),
+			"/ system gnmi-server",
+			"/ system json-rpc-server",
+		)
+	}
+
+	return paths
+}
+
+// checkConfigDrift retrieves the running config and the config of the initial checkpoint in the flat format
+// and returns their difference. The checkpoint is loaded into a private candidate that is discarded afterwards.
+func checkConfigDrift(d *network.Driver, f *srlFeatures, ignore []string) (*configDiff, error) {
+	running, err := d.SendCommand(f.infoFlatCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if running.Failed != nil {
+		return nil, running.Failed
+	}
+
+	r, err := d.SendConfigs([]string{
+		fmt.Sprintf(f.loadCheckpointCmd, initCheckpoint),
+		f.infoFlatCmd,
+		f.discardCmd,
+	}, opoptions.WithStopOnFailed())
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Failed != nil {
+		return nil, r.Failed
+	}
+
+	if len(r.Responses) < 2 { //nolint:mnd
+		return nil, fmt.Errorf("unexpected number of responses retrieving the checkpoint: %d", len(r.Responses)) //nolint:goerr113
+	}
+
+	return diffFlatConfig(r.Responses[1].Result, running.Result, ignore), nil
+}
+
+// configSession is a management session the config is changed over.
+type configSession interface {
+	commandSession
+	SendConfigs(configs []string, opts ...util.Option) (*response.MultiResponse, error)
+}
+
+// revertToInitCheckpoint reverts the node config to the initial checkpoint and saves it.
+func revertToInitCheckpoint(d configSession, f *srlFeatures, log logr.Logger) error {
+	return revertToCheckpoint(d, f, initCheckpoint, log)
+}
+
+// revertToCheckpoint reverts the node config to the named checkpoint and saves it.
+// When the revert fails, the candidate is discarded; a failed discard is returned along with the revert error.
+func revertToCheckpoint(d configSession, f *srlFeatures, name string, log logr.Logger) error {
+	r, err := d.SendConfigs([]string{
+		fmt.Sprintf(f.loadCheckpointCmd, name),
+		f.saveCmd,
+	}, opoptions.WithStopOnFailed())
+	if err == nil && r.Failed == nil {
+		return nil
+	}
+
+	if err == nil {
+		err = r.Failed
+	}
+
+	log.Error(err, "reverting to the checkpoint failed, discarding candidate", "checkpoint", name)
+
+	dr, derr := d.SendConfigs([]string{f.discardCmd})
+	if derr == nil {
+		derr = dr.Failed
+	}
+
+	if derr != nil {
+		log.Error(derr, "failed to discard candidate")
+
+		return errors.Join(err, fmt.Errorf("discarding candidate: %w", derr))
+	}
+
+	return err
+}
+
+// diffFlatConfig returns the config lines added to and removed from the expected config in the running config.
+// Lines under the ignored paths are not compared.
+func diffFlatConfig(expected, running string, ignore []string) *configDiff {
+	exp := flatConfigLines(expected, ignore)
+	run := flatConfigLines(running, ignore)
+
+	diff := &configDiff{}
+
+	for _, l := range run {
+		if _, found := slices.BinarySearch(exp, l); !found {
+			diff.added = append(diff.added, l)
+		}
+	}
+
+	for _, l := range exp {
+		if _, found := slices.BinarySearch(run, l); !found {
+			diff.removed = append(diff.removed, l)
+		}
+	}
+
+	return diff
+}
+
+// flatConfigLines returns the sorted config paths of the config in the flat format,
+// without the paths under the ignored ones.
+func flatConfigLines(cfg string, ignore []string) []string {
+	var lines []string
+
+	for _, l := range strings.Split(cfg, "\n") {
+		// depending on the release, the lines are prefixed with "set" or not
+		l = strings.TrimPrefix(strings.TrimSpace(l), "set ")
+		if !strings.HasPrefix(l, "/") {
+			continue
+		}
+
+		if slices.ContainsFunc(ignore, func(p string) bool { return strings.HasPrefix(l, p) }) {
+			continue
+		}
+
+		lines = append(lines, l)
+	}
+
+	slices.Sort(lines)
+
+	return slices.Compact(lines)
+}
+
+// recordConfigDrift records the config difference in the drift status.
+func recordConfigDrift(st *srlinuxv1.DriftStatus, diff *configDiff) {
+	st.Added = int32(len(diff.added))     //nolint:gosec
+	st.Removed = int32(len(diff.removed)) //nolint:gosec
+	st.Drifted = st.Added > 0 || st.Removed > 0
+	st.Diff = nil
+
+	for _, l := range diff.added {
+		st.Diff = append(st.Diff, "+ "+l)
+	}
+
+	for _, l := range diff.removed {
+		st.Diff = append(st.Diff, "- "+l)
+	}
+
+	if len(st.Diff) > driftDiffMaxLines {
+		st.Diff = st.Diff[:driftDiffMaxLines]
+	}
+}
+
+// driftCondition creates the Drifted condition from the drift status.
+func driftCondition(st *srlinuxv1.DriftStatus, remediate bool) metav1.Condition {
+	switch {
+	case !st.Drifted:
+		return metav1.Condition{
+			Type:    srlinuxv1.ConditionDrifted,
+			Status:  metav1.ConditionFalse,
+			Reason:  srlinuxv1.ReasonConfigInSync,
+			Message: "node config matches the startup config",
+		}
+	case remediate && st.Message == "":
+		return metav1.Condition{
+			Type:    srlinuxv1.ConditionDrifted,
+			Status:  metav1.ConditionFalse,
+			Reason:  srlinuxv1.ReasonRemediated,
+			Message: fmt.Sprintf("node config drifted (%d added, %d removed) and was reverted", st.Added, st.Removed),
+		}
+	}
+
+	return metav1.Condition{
+		Type:    srlinuxv1.ConditionDrifted,
+		Status:  metav1.ConditionTrue,
+		Reason:  srlinuxv1.ReasonConfigDrifted,
+		Message: fmt.Sprintf("node config drifted from the startup config: %d lines added, %d removed", st.Added, st.Removed),
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/scrapli/scrapligo/response"
+	"github.com/scrapli/scrapligo/util"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestDiffFlatConfig(t *testing.T) {
+	expected := `set / interface ethernet-1/1 admin-state enable
+set / system name host-name srl1
+set / system information location lab
+`
+
+	tests := []struct {
+		desc    string
+		running string
+		ignore  []string
+		want    []string
+		drifted bool
+	}{
+		{
+			desc:    "in sync",
+			running: expected,
+		},
+		{
+			desc: "lines without set prefix",
+			running: `/ interface ethernet-1/1 admin-state enable
+/ system name host-name srl1
+/ system information location lab
+`,
+		},
+		{
+			desc: "added and removed lines",
+			running: `set / interface ethernet-1/1 admin-state disable
+set / system name host-name srl1
+set / system information location lab
+`,
+			want: []string{
+				"+ / interface ethernet-1/1 admin-state disable",
+				"- / interface ethernet-1/1 admin-state enable",
+			},
+			drifted: true,
+		},
+		{
+			desc: "ignored paths",
+			running: `set / interface ethernet-1/1 admin-state enable
+set / system name host-name srl1
+set / system information location dc1
+`,
+			ignore: []string{"/ system information"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			st := &srlinuxv1.DriftStatus{}
+			recordConfigDrift(st, diffFlatConfig(expected, tt.running, tt.ignore))
+
+			if st.Drifted != tt.drifted || !cmp.Equal(st.Diff, tt.want) {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc, st.Diff, tt.want)
+			}
+		},
+		)
+	}
+}
+
+func TestDriftCondition(t *testing.T) {
+	tests := []struct {
+		desc       string
+		st         *srlinuxv1.DriftStatus
+		remediate  bool
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			desc:       "in sync",
+			st:         &srlinuxv1.DriftStatus{},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: srlinuxv1.ReasonConfigInSync,
+		},
+		{
+			desc:       "drifted",
+			st:         &srlinuxv1.DriftStatus{Drifted: true, Added: 1},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: srlinuxv1.ReasonConfigDrifted,
+		},
+		{
+			desc:       "remediated",
+			st:         &srlinuxv1.DriftStatus{Drifted: true, Added: 1},
+			remediate:  true,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: srlinuxv1.ReasonRemediated,
+		},
+		{
+			desc:       "remediation failed",
+			st:         &srlinuxv1.DriftStatus{Drifted: true, Added: 1, Message: "remediation failed: commit failed"},
+			remediate:  true,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: srlinuxv1.ReasonConfigDrifted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := driftCondition(tt.st, tt.remediate)
+			if c.Status != tt.wantStatus || c.Reason != tt.wantReason {
+				t.Fatalf("%s: unexpected condition %s/%s", tt.desc, c.Status, c.Reason)
+			}
+		},
+		)
+	}
+}
+
+func TestHandleSrlinuxDriftSchedule(t *testing.T) {
+	lastCheck := metav1.NewTime(time.Now().Add(-time.Minute))
+
+	tests := []struct {
+		desc        string
+		spec        *srlinuxv1.DriftSpec
+		phase       string
+		status      *srlinuxv1.DriftStatus
+		wantUpdate  bool
+		wantRequeue bool
+	}{
+		{
+			desc:       "drift check disabled clears the status",
+			status:     &srlinuxv1.DriftStatus{Drifted: true},
+			phase:      "loaded",
+			wantUpdate: true,
+		},
+		{
+			desc:  "startup config not processed",
+			spec:  &srlinuxv1.DriftSpec{},
+			phase: "retrying",
+		},
+		{
+			desc:        "interval not elapsed",
+			spec:        &srlinuxv1.DriftSpec{},
+			phase:       "loaded",
+			status:      &srlinuxv1.DriftStatus{LastCheckTime: &lastCheck},
+			wantRequeue: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &SrlinuxReconciler{}
+			update := false
+			srl := &srlinuxv1.Srlinux{
+				Spec: srlinuxv1.SrlinuxSpec{Drift: tt.spec},
+				Status: srlinuxv1.SrlinuxStatus{
+					StartupConfig: srlinuxv1.StartupConfigStatus{Phase: tt.phase},
+					Drift:         tt.status,
+				},
+			}
+
+			requeue := r.handleSrlinuxDrift(ctx, ctrl.Log, &update, srl, nil)
+
+			if update != tt.wantUpdate || (requeue > 0) != tt.wantRequeue {
+				t.Fatalf("%s: unexpected update %v or requeue %v", tt.desc, update, requeue)
+			}
+
+			if tt.spec == nil && srl.Status.Drift != nil {
+				t.Fatalf("%s: drift status is not cleared", tt.desc)
+			}
+		},
+		)
+	}
+}
+
+// fakeConfigSession records the commands and configs sent and replies to them with the results,
+// "ok" by default. The commands in failed fail the given number of times,
+// the configs sent after a failed one are not sent.
+type fakeConfigSession struct {
+	sent    []string
+	results map[string]string
+	failed  map[string]int
+}
+
+func (s *fakeConfigSession) SendCommand(command string, _ ...util.Option) (*response.Response, error) {
+	s.sent = append(s.sent, command)
+
+	r := &response.Response{Input: command, Result: "ok"}
+	if res, ok := s.results[command]; ok {
+		r.Result = res
+	}
+
+	if s.failed[command] > 0 {
+		s.failed[command]--
+		r.Result, r.Failed = "Error: "+command+" failed", errors.New(command+" failed") //nolint:goerr113
+	}
+
+	return r, nil
+}
+
+func (s *fakeConfigSession) SendConfigs(configs []string, _ ...util.Option) (*response.MultiResponse, error) {
+	mr := &response.MultiResponse{}
+
+	for _, c := range configs {
+		r, _ := s.SendCommand(c)
+		mr.Responses = append(mr.Responses, r)
+
+		if r.Failed != nil {
+			mr.Failed = r.Failed
+
+			break
+		}
+	}
+
+	return mr, nil
+}
+
+func TestRevertToInitCheckpoint(t *testing.T) {
+	f := featuresFor(nil)
+	load := "load checkpoint name " + initCheckpoint
+
+	tests := []struct {
+		desc     string
+		failed   map[string]int
+		wantSent []string
+		wantErr  []string
+	}{
+		{
+			desc:     "reverted",
+			wantSent: []string{load, f.saveCmd},
+		},
+		{
+			desc:     "revert failed and candidate discarded",
+			failed:   map[string]int{f.saveCmd: 1},
+			wantSent: []string{load, f.saveCmd, f.discardCmd},
+			wantErr:  []string{f.saveCmd + " failed"},
+		},
+		{
+			desc:     "revert and discard failed",
+			failed:   map[string]int{load: 1, f.discardCmd: 1},
+			wantSent: []string{load, f.discardCmd},
+			wantErr:  []string{load + " failed", "discarding candidate: " + f.discardCmd + " failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := &fakeConfigSession{failed: tt.failed}
+
+			err := revertToInitCheckpoint(d, f, ctrl.Log)
+
+			var gotErr []string
+			if err != nil {
+				gotErr = strings.Split(err.Error(), "\n")
+			}
+
+			if !cmp.Equal(d.sent, tt.wantSent) || !cmp.Equal(gotErr, tt.wantErr) {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %+v %+v\nexpected:%+v %+v",
+					tt.desc, d.sent, gotErr, tt.wantSent, tt.wantErr)
+			}
+		},
+		)
+	}
+}
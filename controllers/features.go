@@ -0,0 +1,116 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+)
+
+// srlFeatures describes the release specific behavior of SR Linux the controller relies on.
+type srlFeatures struct {
+	// versions is a set of version constraints the features apply to.
+	versions srlinuxv1.VersionConstraints
+	// readinessCmd is a command that succeeds when the management server is ready to accept config.
+	readinessCmd []string
+	// loadJSONCmd is a format string of a command that loads a JSON-styled config file,
+	// replacing the candidate.
+	loadJSONCmd string
+	// mergeJSONCmd is a format string of a command that merges a JSON-styled config file into the candidate.
+	mergeJSONCmd string
+	// loadCLICmd is a format string of a command that loads a CLI-styled config file.
+	loadCLICmd string
+	// saveCmd is a command that commits and saves the loaded configuration.
+	saveCmd string
+	// discardCmd is a command that discards the uncommitted changes of the candidate.
+	discardCmd string
+	// checkpointCmd is a format string of a command that creates a named checkpoint.
+	checkpointCmd string
+	// getCheckpointsCmd is a command that lists existing checkpoints.
+	getCheckpointsCmd string
+	// loadCheckpointCmd is a format string of a command that loads a named checkpoint into the candidate.
+	loadCheckpointCmd string
+	// infoFlatCmd is a command that retrieves the config in the flat format,
+	// of the running datastore in the running mode and of the candidate in the candidate mode.
+	infoFlatCmd string
+	// runningJSONCmd is a command that retrieves the running config in the JSON format.
+	runningJSONCmd string
+	// runningFlatCmd is a command that retrieves the running config in the CLI flat format.
+	runningFlatCmd string
+	// getVersionCmd is a command that retrieves the software version of the node.
+	getVersionCmd string
+	// livenessCmd is a format string of a shell command that succeeds when the application is running.
+	livenessCmd string
+	// appStateCmd is a format string of a command that retrieves the state of an application.
+	appStateCmd string
+	// interfaceOperStateCmd is a format string of a command that retrieves the oper-state of an interface.
+	interfaceOperStateCmd string
+	// bgpSessionStateCmd is a format string of a command that retrieves the session state of a BGP neighbor
+	// in a network instance.
+	bgpSessionStateCmd string
+}
+
+// latestFeatures are the features of the releases since 21.3, the older releases differ in a few of them.
+var latestFeatures = srlFeatures{ //nolint:gochecknoglobals
+	versions: srlinuxv1.MustParseVersionConstraints(">=21.3"),
+	// the management server creates the file once it is ready to accept config
+	readinessCmd:          []string{"cat", "/etc/opt/srlinux/devices/app_ephemeral.mgmt_server.ready_for_config"},
+	loadJSONCmd:           "load file %s",
+	mergeJSONCmd:          "load file %s merge",
+	loadCLICmd:            "source %s",
+	saveCmd:               "commit save",
+	discardCmd:            "discard now",
+	checkpointCmd:         "/tools system configuration generate-checkpoint name %s",
+	getCheckpointsCmd:     "info from state system configuration checkpoint *",
+	loadCheckpointCmd:     "load checkpoint name %s",
+	infoFlatCmd:           "info flat",
+	runningJSONCmd:        "info from running / | as json",
+	runningFlatCmd:        "info flat from running /",
+	getVersionCmd:         "info from state system information version",
+	livenessCmd:           `sr_cli -d "info from state system app-management application %s state" | grep -qw running`,
+	appStateCmd:           "info from state system app-management application %s state",
+	interfaceOperStateCmd: "info from state interface %s oper-state",
+	bgpSessionStateCmd:    "info from state network-instance %s protocols bgp neighbor %s session-state",
+}
+
+// srlFeatureTable is a list of SR Linux features keyed by version.
+// The entries are evaluated in order and the first entry which constraints
+// are satisfied by the SR Linux version is used.
+// Engineering builds and versions that couldn't be determined use the first entry,
+// hence the table should be sorted from newest to oldest releases.
+var srlFeatureTable = []*srlFeatures{ //nolint:gochecknoglobals
+	&latestFeatures,
+	latestFeatures.with("<21.3", func(f *srlFeatures) {
+		// older releases don't create the ready_for_config file,
+		// the readiness is derived from the state of the management server application
+		f.readinessCmd = []string{
+			"bash",
+			"-c",
+			`sr_cli -d "info from state system app-management application mgmt_server state" | grep -qw running`,
+		}
+	}),
+}
+
+// with returns a copy of the features applying to the given versions, changed by the override.
+func (f srlFeatures) with(versions string, override func(f *srlFeatures)) *srlFeatures {
+	f.versions = srlinuxv1.MustParseVersionConstraints(versions)
+	override(&f)
+
+	return &f
+}
+
+// featuresFor returns SR Linux features for a given version.
+func featuresFor(v *srlinuxv1.SrlVersion) *srlFeatures {
+	if v == nil || v.IsEngineering() {
+		return srlFeatureTable[0]
+	}
+
+	for _, f := range srlFeatureTable {
+		if f.versions.Check(v) {
+			return f
+		}
+	}
+
+	return srlFeatureTable[len(srlFeatureTable)-1]
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"testing"
+
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+)
+
+func TestFeaturesFor(t *testing.T) {
+	tests := []struct {
+		desc    string
+		version *srlinuxv1.SrlVersion
+		want    *srlFeatures
+	}{
+		{
+			desc: "unknown version",
+			want: srlFeatureTable[0],
+		},
+		{
+			desc:    "engineering build",
+			version: &srlinuxv1.SrlVersion{Major: "0"},
+			want:    srlFeatureTable[0],
+		},
+		{
+			desc:    "newest release",
+			version: &srlinuxv1.SrlVersion{Major: "24", Minor: "3", Patch: "2"},
+			want:    srlFeatureTable[0],
+		},
+		{
+			desc:    "lower boundary of the ready_for_config file",
+			version: &srlinuxv1.SrlVersion{Major: "21", Minor: "3"},
+			want:    srlFeatureTable[0],
+		},
+		{
+			desc:    "last release without the ready_for_config file",
+			version: &srlinuxv1.SrlVersion{Major: "21", Minor: "2", Patch: "9"},
+			want:    srlFeatureTable[1],
+		},
+		{
+			desc:    "old release",
+			version: &srlinuxv1.SrlVersion{Major: "20", Minor: "10", Patch: "1"},
+			want:    srlFeatureTable[1],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			f := featuresFor(tt.version)
+			if f != tt.want {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc, f.versions, tt.want.versions)
+			}
+		},
+		)
+	}
+}
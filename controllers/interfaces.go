@@ -0,0 +1,111 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/scrapli/scrapligo/driver/network"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+)
+
+// multusNetworksAnnotation is the pod annotation Multus uses to attach additional networks to the pod.
+const multusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+
+// multusNetwork is a network selection element of the Multus networks annotation.
+type multusNetwork struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Interface string `json:"interface,omitempty"`
+	MAC       string `json:"mac,omitempty"`
+}
+
+// createMultusNetworks returns the value of Multus networks annotation for the interfaces
+// that reference a network attachment definition. The interfaces are named inside the pod
+// the way SR Linux expects them, e.g. ethernet-1/1 is attached as e1-1.
+// Empty string is returned when none of the interfaces reference a network.
+func createMultusNetworks(s *srlinuxv1.Srlinux) (string, error) {
+	var nets []multusNetwork
+
+	for i := range s.Spec.Interfaces {
+		intf := &s.Spec.Interfaces[i]
+		if intf.Network == "" {
+			continue
+		}
+
+		ifName, err := intf.LinuxName()
+		if err != nil {
+			return "", err
+		}
+
+		ns, name := intf.NetworkNamespacedName()
+
+		nets = append(nets, multusNetwork{
+			Name:      name,
+			Namespace: ns,
+			Interface: ifName,
+			MAC:       intf.MAC,
+		})
+	}
+
+	if nets == nil {
+		return "", nil
+	}
+
+	b, err := json.Marshal(nets)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// createInterfaceCmds creates the commands that configure interface parameters declared in the spec.
+func createInterfaceCmds(s *srlinuxv1.Srlinux) []string {
+	var cmds []string
+
+	for _, intf := range s.Spec.Interfaces {
+		if intf.MTU != 0 {
+			cmds = append(cmds, fmt.Sprintf("set / interface %s mtu %d", intf.Name, intf.MTU))
+		}
+	}
+
+	return cmds
+}
+
+// applyInterfaceConfig configures interface parameters declared in the spec on the node
+// and saves the configuration.
+func applyInterfaceConfig(
+	_ context.Context,
+	d *network.Driver,
+	f *srlFeatures,
+	s *srlinuxv1.Srlinux,
+	log logr.Logger,
+) error {
+	cmds := createInterfaceCmds(s)
+	if cmds == nil {
+		return nil
+	}
+
+	log.Info("Applying interface configuration...")
+
+	r, err := d.SendConfigs(append(cmds, f.saveCmd))
+	if err != nil {
+		log.Error(err, "failed to send commands")
+
+		return err
+	}
+
+	if r.Failed != nil {
+		log.Error(r.Failed, "applying commands failed")
+
+		return r.Failed
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+)
+
+func TestCreateMultusNetworks(t *testing.T) {
+	tests := []struct {
+		desc       string
+		interfaces []srlinuxv1.InterfaceSpec
+		want       string
+		wantErr    bool
+	}{
+		{
+			desc: "no interfaces",
+			want: "",
+		},
+		{
+			desc: "interfaces without networks",
+			interfaces: []srlinuxv1.InterfaceSpec{
+				{Name: "ethernet-1/1"},
+			},
+			want: "",
+		},
+		{
+			desc: "interfaces with networks",
+			interfaces: []srlinuxv1.InterfaceSpec{
+				{Name: "ethernet-1/1", Network: "srl1-srl2"},
+				{Name: "ethernet-1/2"},
+				{Name: "ethernet-1/3/1", Network: "fabric/srl1-srl3"},
+			},
+			want: `[{"name":"srl1-srl2","interface":"e1-1"},` +
+				`{"name":"srl1-srl3","namespace":"fabric","interface":"e1-3-1"}]`,
+		},
+		{
+			desc: "invalid interface name",
+			interfaces: []srlinuxv1.InterfaceSpec{
+				{Name: "eth1", Network: "srl1-srl2"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			srl := &srlinuxv1.Srlinux{Spec: srlinuxv1.SrlinuxSpec{Interfaces: tt.interfaces}}
+
+			nets, err := createMultusNetworks(srl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if !cmp.Equal(nets, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					nets,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
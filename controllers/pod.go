@@ -7,11 +7,13 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -23,17 +25,21 @@ const (
 	licenseFileName               = "license.key"
 	licenseMntPath                = "/opt/srlinux/etc/license.key"
 	licenseMntSubPath             = "license.key"
-	readinessFile                 = "/etc/opt/srlinux/devices/app_ephemeral.mgmt_server.ready_for_config"
-	readinessInitialDelay         = 10
-	readinessPeriodSeconds        = 5
-	readinessFailureThreshold     = 10
 )
 
+// livenessApps maps liveness checks to the SR Linux applications they check.
+//
+//nolint:gochecknoglobals
+var livenessApps = map[string]string{
+	srlinuxv1.LivenessCheckMgmtServer: "mgmt_server",
+	srlinuxv1.LivenessCheckAppManager: "app_mgr",
+}
+
 // podForSrlinux returns a srlinux Pod object.
 func (r *SrlinuxReconciler) podForSrlinux(
 	ctx context.Context,
 	s *srlinuxv1.Srlinux,
-) *corev1.Pod {
+) (*corev1.Pod, error) {
 	log := log.FromContext(ctx)
 
 	if s.Spec.Config.Env == nil {
@@ -42,8 +48,17 @@ func (r *SrlinuxReconciler) podForSrlinux(
 
 	s.Spec.Config.Env["SRLINUX"] = "1" // set default srlinux env var
 
+	if s.Spec.Storage != nil {
+		s.Spec.Config.Env[persistentStorageEnv] = "1"
+	}
+
+	meta, err := createObjectMeta(s)
+	if err != nil {
+		return nil, err
+	}
+
 	pod := &corev1.Pod{
-		ObjectMeta: createObjectMeta(s),
+		ObjectMeta: meta,
 		Spec: corev1.PodSpec{
 			InitContainers:                createInitContainers(s),
 			Containers:                    createContainers(s),
@@ -51,23 +66,24 @@ func (r *SrlinuxReconciler) podForSrlinux(
 			NodeSelector:                  map[string]string{},
 			Affinity:                      createAffinity(s),
 			Volumes:                       createVolumes(s),
+			ImagePullSecrets:              r.createImagePullSecrets(ctx, s),
 		},
 	}
 
 	// handle startup config volume mounts if the startup config was defined
-	if s.Spec.Config.ConfigDataPresent {
+	if s.Spec.HasStartupConfig() {
 		createStartupConfigVolumesAndMounts(s, pod, log)
 	}
 
-	//nolint:godox
-	// TODO: handle the error
-	_ = ctrl.SetControllerReference(s, pod, r.Scheme)
+	if err := ctrl.SetControllerReference(s, pod, r.Scheme); err != nil {
+		return nil, err
+	}
 
-	return pod
+	return pod, nil
 }
 
-func createObjectMeta(s *srlinuxv1.Srlinux) metav1.ObjectMeta {
-	return metav1.ObjectMeta{
+func createObjectMeta(s *srlinuxv1.Srlinux) (metav1.ObjectMeta, error) {
+	meta := metav1.ObjectMeta{
 		Name:      s.Name,
 		Namespace: s.Namespace,
 		Labels: map[string]string{
@@ -75,6 +91,20 @@ func createObjectMeta(s *srlinuxv1.Srlinux) metav1.ObjectMeta {
 			"topo": s.Namespace,
 		},
 	}
+
+	// interfaces attached with multus are requested via pod annotation
+	nets, err := createMultusNetworks(s)
+	if err != nil {
+		return meta, err
+	}
+
+	if nets != "" {
+		meta.Annotations = map[string]string{
+			multusNetworksAnnotation: nets,
+		}
+	}
+
+	return meta, nil
 }
 
 func createInitContainers(s *srlinuxv1.Srlinux) []corev1.Container {
@@ -82,14 +112,16 @@ func createInitContainers(s *srlinuxv1.Srlinux) []corev1.Container {
 		Name:  fmt.Sprintf("init-%s", s.Name),
 		Image: s.Spec.GetInitImage(),
 		Args: []string{
-			fmt.Sprintf("%d", s.Spec.NumInterfaces+1),
+			fmt.Sprintf("%d", s.Spec.GetNumInterfaces()+1),
 			fmt.Sprintf("%d", s.Spec.Config.Sleep),
 		},
-		ImagePullPolicy: "IfNotPresent",
+		ImagePullPolicy: s.Spec.GetConfig().GetImagePullPolicy(),
 	}}
 }
 
 func createContainers(s *srlinuxv1.Srlinux) []corev1.Container {
+	f := featuresFor(s.GetVersion())
+
 	return []corev1.Container{{
 		Name:            s.Name,
 		Image:           s.Spec.GetImage(),
@@ -97,26 +129,85 @@ func createContainers(s *srlinuxv1.Srlinux) []corev1.Container {
 		Args:            s.Spec.Config.GetArgs(),
 		Env:             toEnvVar(s.Spec.Config.Env),
 		Resources:       toResourceRequirements(s.Spec.GetConstraints()),
-		ImagePullPolicy: "IfNotPresent",
+		ImagePullPolicy: s.Spec.Config.GetImagePullPolicy(),
 		SecurityContext: &corev1.SecurityContext{
 			Privileged: ptr.To(true),
 			RunAsUser:  ptr.To(int64(0)),
 		},
-		VolumeMounts: createVolumeMounts(s),
-		ReadinessProbe: &corev1.Probe{
-			ProbeHandler: corev1.ProbeHandler{
-				Exec: &corev1.ExecAction{
-					Command: []string{
-						"cat",
-						readinessFile,
-					},
-				},
+		VolumeMounts:   createVolumeMounts(s),
+		StartupProbe:   createProbe(s.Spec.GetStartupProbe(), readinessHandler(f)),
+		ReadinessProbe: createProbe(s.Spec.GetReadinessProbe(), readinessHandler(f)),
+		LivenessProbe:  createLivenessProbe(s, f),
+	}}
+}
+
+// readinessHandler creates a probe handler that checks the management server is ready to accept config.
+func readinessHandler(f *srlFeatures) corev1.ProbeHandler {
+	return corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{
+			Command: f.readinessCmd,
+		},
+	}
+}
+
+// createLivenessProbe creates a liveness probe checking the SR Linux application is running.
+// Nil is returned if the liveness probe is not defined in the spec.
+func createLivenessProbe(s *srlinuxv1.Srlinux, f *srlFeatures) *corev1.Probe {
+	p := s.Spec.GetLivenessProbe()
+	if p == nil {
+		return nil
+	}
+
+	return createProbe(p.ProbeSpec, corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{
+			Command: []string{
+				"bash",
+				"-c",
+				fmt.Sprintf(f.livenessCmd, livenessApps[p.Check]),
 			},
-			InitialDelaySeconds: readinessInitialDelay,
-			PeriodSeconds:       readinessPeriodSeconds,
-			FailureThreshold:    readinessFailureThreshold,
 		},
-	}}
+	})
+}
+
+// createProbe creates a container probe with the given timings and handler.
+func createProbe(p srlinuxv1.ProbeSpec, h corev1.ProbeHandler) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler:        h,
+		InitialDelaySeconds: ptr.Deref(p.InitialDelaySeconds, 0),
+		PeriodSeconds:       ptr.Deref(p.PeriodSeconds, 0),
+		TimeoutSeconds:      ptr.Deref(p.TimeoutSeconds, 0),
+		FailureThreshold:    ptr.Deref(p.FailureThreshold, 0),
+	}
+}
+
+// createImagePullSecrets returns image pull secrets defined in the spec
+// followed by the controller-wide default image pull secret, if set and copied to the Srlinux namespace.
+func (r *SrlinuxReconciler) createImagePullSecrets(
+	ctx context.Context,
+	s *srlinuxv1.Srlinux,
+) []corev1.LocalObjectReference {
+	secrets := slices.Clone(s.Spec.GetConfig().ImagePullSecrets)
+
+	if r.ImagePullSecret == "" {
+		return secrets
+	}
+
+	// the copy is skipped when the default secret is missing in the controller namespace,
+	// a reference to the missing secret would only make the kubelet report failures
+	err := r.Get(ctx, types.NamespacedName{Name: r.ImagePullSecret, Namespace: s.Namespace}, &corev1.Secret{})
+	if err != nil {
+		log.FromContext(ctx).Info("default image pull secret is not available in the namespace, not referencing it",
+			"secret name", r.ImagePullSecret, "error", err.Error())
+
+		return secrets
+	}
+
+	dflt := corev1.LocalObjectReference{Name: r.ImagePullSecret}
+	if !slices.Contains(secrets, dflt) {
+		secrets = append(secrets, dflt)
+	}
+
+	return secrets
 }
 
 func createAffinity(s *srlinuxv1.Srlinux) *corev1.Affinity {
@@ -186,6 +277,14 @@ func createVolumes(s *srlinuxv1.Srlinux) []corev1.Volume {
 		vols = append(vols, createLicenseVolume(s))
 	}
 
+	if s.Spec.GetConfig().Cert != nil {
+		vols = append(vols, createTLSVolume(s))
+	}
+
+	if s.Spec.Storage != nil {
+		vols = append(vols, createStorageVolume(s))
+	}
+
 	return vols
 }
 
@@ -210,6 +309,14 @@ func createVolumeMounts(s *srlinuxv1.Srlinux) []corev1.VolumeMount {
 		vms = append(vms, createLicenseVolumeMount())
 	}
 
+	if s.Spec.GetConfig().Cert != nil {
+		vms = append(vms, createTLSVolumeMount())
+	}
+
+	if s.Spec.Storage != nil {
+		vms = append(vms, createStorageVolumeMounts()...)
+	}
+
 	return vms
 }
 
@@ -0,0 +1,216 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podEvictedReason is the reason kubelet sets in the status of evicted pods.
+const podEvictedReason = "Evicted"
+
+// handleSrlinuxPodStatus surfaces restarts and terminations of the SR Linux container
+// and init container failures in the Srlinux status and conditions.
+// A restarted SR Linux container loses its running config, therefore
+// provisioning is reset to have the startup config applied again.
+func handleSrlinuxPodStatus(
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) {
+	if c, ok := initializedCondition(pod); ok {
+		c.ObservedGeneration = srlinux.Generation
+
+		if meta.SetStatusCondition(&srlinux.Status.Conditions, c) {
+			*update = true
+		}
+	}
+
+	cs := srlinuxContainerStatus(srlinux, pod)
+	if cs == nil {
+		return
+	}
+
+	if cs.RestartCount > srlinux.Status.Restarts {
+		log.Info("SR Linux container restarted, re-provisioning the node", "restarts", cs.RestartCount)
+
+		resetProvisioning(srlinux)
+	}
+
+	if srlinux.Status.Restarts != cs.RestartCount {
+		*update = true
+		srlinux.Status.Restarts = cs.RestartCount
+	}
+
+	if t := lastTermination(cs); t != nil && !cmp.Equal(srlinux.Status.LastTermination, t) {
+		*update = true
+		srlinux.Status.LastTermination = t
+	}
+
+	c := restartedCondition(cs.RestartCount, srlinux.Status.LastTermination)
+	c.ObservedGeneration = srlinux.Generation
+
+	if meta.SetStatusCondition(&srlinux.Status.Conditions, c) {
+		*update = true
+	}
+}
+
+// resetProvisioning resets the status of the provisioning steps performed over the management session,
+// so that they are performed again once the node, which lost its running config, becomes ready.
+func resetProvisioning(srlinux *srlinuxv1.Srlinux) {
+	srlinux.Status.StartupConfig = srlinuxv1.StartupConfigStatus{}
+	srlinux.Status.Ready = false
+	srlinux.Status.Readiness = nil
+	srlinux.Status.Management.Endpoints = nil
+	srlinux.Status.Management.EndpointsProbeTime = nil
+
+	if srlinux.Status.Certificate != nil {
+		srlinux.Status.Certificate.InstalledSerial = ""
+	}
+}
+
+// handleSrlinuxPodRecreation resets provisioning when the startup config was processed on another pod,
+// as the re-created pod runs with no config applied.
+func handleSrlinuxPodRecreation(
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) {
+	uid := srlinux.Status.StartupConfig.PodUID
+	if uid == "" || uid == pod.UID {
+		return
+	}
+
+	log.Info("pod was re-created, re-provisioning the node", "old pod uid", uid, "pod uid", pod.UID)
+
+	resetProvisioning(srlinux)
+
+	*update = true
+}
+
+// recordStartupConfigPod records the UID of the pod the startup config was processed on.
+func recordStartupConfigPod(update *bool, srlinux *srlinuxv1.Srlinux, pod *corev1.Pod) {
+	if srlinux.Status.StartupConfig.Phase == "" || srlinux.Status.StartupConfig.PodUID == pod.UID {
+		return
+	}
+
+	srlinux.Status.StartupConfig.PodUID = pod.UID
+	*update = true
+}
+
+// isPodEvicted returns true if the pod was evicted by kubelet.
+// Evicted pods are not restarted, they remain in the Failed phase.
+func isPodEvicted(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == podEvictedReason
+}
+
+// srlinuxContainerStatus returns the status of the SR Linux container of the pod.
+func srlinuxContainerStatus(srlinux *srlinuxv1.Srlinux, pod *corev1.Pod) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == srlinux.Name {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+
+	return nil
+}
+
+// lastTermination returns the last termination of the container, nil is returned if it never terminated.
+func lastTermination(cs *corev1.ContainerStatus) *srlinuxv1.TerminationStatus {
+	t := cs.State.Terminated
+	if t == nil {
+		t = cs.LastTerminationState.Terminated
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return &srlinuxv1.TerminationStatus{
+		Reason:     t.Reason,
+		Message:    t.Message,
+		ExitCode:   t.ExitCode,
+		OOMKilled:  t.Reason == srlinuxv1.ReasonOOMKilled,
+		FinishedAt: t.FinishedAt,
+	}
+}
+
+// restartedCondition creates the Restarted condition for the container restart count and last termination.
+func restartedCondition(restarts int32, t *srlinuxv1.TerminationStatus) metav1.Condition {
+	if restarts == 0 {
+		return metav1.Condition{
+			Type:    srlinuxv1.ConditionRestarted,
+			Status:  metav1.ConditionFalse,
+			Reason:  srlinuxv1.ReasonNotRestarted,
+			Message: "SR Linux container has not been restarted",
+		}
+	}
+
+	c := metav1.Condition{
+		Type:    srlinuxv1.ConditionRestarted,
+		Status:  metav1.ConditionTrue,
+		Reason:  srlinuxv1.ConditionRestarted,
+		Message: fmt.Sprintf("SR Linux container restarted %d times", restarts),
+	}
+
+	if t != nil {
+		if t.Reason != "" {
+			c.Reason = t.Reason
+		}
+
+		c.Message = fmt.Sprintf("%s, last exit code %d", c.Message, t.ExitCode)
+	}
+
+	return c
+}
+
+// initializedCondition creates the Initialized condition from the status of the init container.
+// False is returned if the condition can't be determined yet, e.g. while the init container is running.
+func initializedCondition(pod *corev1.Pod) (metav1.Condition, bool) {
+	if len(pod.Status.InitContainerStatuses) == 0 {
+		return metav1.Condition{}, false
+	}
+
+	cs := pod.Status.InitContainerStatuses[0]
+
+	c := metav1.Condition{Type: srlinuxv1.ConditionInitialized}
+
+	switch {
+	case cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0:
+		c.Status = metav1.ConditionTrue
+		c.Reason = srlinuxv1.ReasonInitCompleted
+		c.Message = "init container completed"
+	case cs.State.Terminated != nil:
+		c.Status = metav1.ConditionFalse
+		c.Reason = reasonOr(cs.State.Terminated.Reason, "Error")
+		c.Message = fmt.Sprintf("init container exited with code %d", cs.State.Terminated.ExitCode)
+	case cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing":
+		c.Status = metav1.ConditionFalse
+		c.Reason = cs.State.Waiting.Reason
+		c.Message = reasonOr(cs.State.Waiting.Message, "init container is waiting")
+	default:
+		return metav1.Condition{}, false
+	}
+
+	return c, true
+}
+
+// reasonOr returns the reason if it is set, the default is returned otherwise.
+func reasonOr(reason, dflt string) string {
+	if reason != "" {
+		return reason
+	}
+
+	return dflt
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandleSrlinuxPodStatus(t *testing.T) {
+	newPod := func(restarts int32, last *corev1.ContainerStateTerminated, initState corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{{Name: "init-srl1", State: initState}},
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:                 "srl1",
+					RestartCount:         restarts,
+					LastTerminationState: corev1.ContainerState{Terminated: last},
+				}},
+			},
+		}
+	}
+
+	initDone := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}
+
+	tests := []struct {
+		desc            string
+		restarts        int32
+		pod             *corev1.Pod
+		wantPhase       string
+		wantRestarted   metav1.ConditionStatus
+		wantReason      string
+		wantInitialized metav1.ConditionStatus
+		wantOOMKilled   bool
+	}{
+		{
+			desc:            "no restarts",
+			pod:             newPod(0, nil, initDone),
+			wantPhase:       "loaded",
+			wantRestarted:   metav1.ConditionFalse,
+			wantReason:      srlinuxv1.ReasonNotRestarted,
+			wantInitialized: metav1.ConditionTrue,
+		},
+		{
+			desc: "container OOM killed",
+			pod: newPod(1, &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+				initDone),
+			wantPhase:       "",
+			wantRestarted:   metav1.ConditionTrue,
+			wantReason:      srlinuxv1.ReasonOOMKilled,
+			wantInitialized: metav1.ConditionTrue,
+			wantOOMKilled:   true,
+		},
+		{
+			desc:     "restart already handled",
+			restarts: 1,
+			pod: newPod(1, &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1},
+				initDone),
+			wantPhase:       "loaded",
+			wantRestarted:   metav1.ConditionTrue,
+			wantReason:      "Error",
+			wantInitialized: metav1.ConditionTrue,
+		},
+		{
+			desc: "init container failed",
+			pod: newPod(0, nil,
+				corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}}),
+			wantPhase:       "loaded",
+			wantRestarted:   metav1.ConditionFalse,
+			wantReason:      srlinuxv1.ReasonNotRestarted,
+			wantInitialized: metav1.ConditionFalse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			srl := &srlinuxv1.Srlinux{
+				ObjectMeta: metav1.ObjectMeta{Name: "srl1"},
+				Status: srlinuxv1.SrlinuxStatus{
+					Restarts:      tt.restarts,
+					StartupConfig: srlinuxv1.StartupConfigStatus{Phase: "loaded"},
+				},
+			}
+
+			update := false
+
+			handleSrlinuxPodStatus(logr.Discard(), &update, srl, tt.pod)
+
+			if srl.Status.StartupConfig.Phase != tt.wantPhase {
+				t.Fatalf("%s: expected startup config phase %q, got %q",
+					tt.desc, tt.wantPhase, srl.Status.StartupConfig.Phase)
+			}
+
+			c := meta.FindStatusCondition(srl.Status.Conditions, srlinuxv1.ConditionRestarted)
+			if c == nil || c.Status != tt.wantRestarted || c.Reason != tt.wantReason {
+				t.Fatalf("%s: unexpected Restarted condition: %+v", tt.desc, c)
+			}
+
+			c = meta.FindStatusCondition(srl.Status.Conditions, srlinuxv1.ConditionInitialized)
+			if c == nil || c.Status != tt.wantInitialized {
+				t.Fatalf("%s: unexpected Initialized condition: %+v", tt.desc, c)
+			}
+
+			oom := srl.Status.LastTermination != nil && srl.Status.LastTermination.OOMKilled
+			if oom != tt.wantOOMKilled {
+				t.Fatalf("%s: expected OOM killed %v, got %v", tt.desc, tt.wantOOMKilled, oom)
+			}
+
+			if !update {
+				t.Fatalf("%s: expected status update", tt.desc)
+			}
+		},
+		)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/scrapli/scrapligo/driver/network"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// readinessRetryInterval is the interval readiness stages are re-evaluated at until they pass.
+const readinessRetryInterval = 10 * time.Second
+
+// readinessCheck is a readiness stage evaluated over the management session.
+// The stage passes when the leaf in the output of the command has the wanted value.
+type readinessCheck struct {
+	name string
+	cmd  string
+	leaf string
+	want string
+}
+
+// isPodReady returns true if the srlinux container of the pod is ready,
+// that is when the management server is ready to accept config.
+func isPodReady(pod *corev1.Pod) bool {
+	return len(pod.Status.ContainerStatuses) > 0 && pod.Status.ContainerStatuses[0].Ready
+}
+
+// readinessChecks creates the readiness checks for the stages configured in the spec.
+func readinessChecks(s *srlinuxv1.Srlinux, f *srlFeatures) []readinessCheck {
+	rs := s.Spec.Readiness
+	if !rs.HasStages() {
+		return nil
+	}
+
+	checks := make([]readinessCheck, 0, len(rs.Apps)+len(rs.Interfaces)+len(rs.BGPNeighbors))
+
+	for _, app := range rs.Apps {
+		checks = append(checks, readinessCheck{
+			name: "app/" + app,
+			cmd:  fmt.Sprintf(f.appStateCmd, app),
+			leaf: "state",
+			want: "running",
+		})
+	}
+
+	for _, intf := range rs.Interfaces {
+		checks = append(checks, readinessCheck{
+			name: "interface/" + intf,
+			cmd:  fmt.Sprintf(f.interfaceOperStateCmd, intf),
+			leaf: "oper-state",
+			want: "up",
+		})
+	}
+
+	for i := range rs.BGPNeighbors {
+		n := &rs.BGPNeighbors[i]
+
+		checks = append(checks, readinessCheck{
+			name: n.StageName(),
+			cmd:  fmt.Sprintf(f.bgpSessionStateCmd, n.GetNetworkInstance(), n.Address),
+			leaf: "session-state",
+			want: "established",
+		})
+	}
+
+	return checks
+}
+
+// handleSrlinuxReadiness evaluates the readiness stages configured in the spec
+// and records their status. The Srlinux is ready only when all stages are passed.
+// The returned duration is the time after which the stages need to be re-evaluated; 0 means never.
+func (r *SrlinuxReconciler) handleSrlinuxReadiness(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) time.Duration {
+	checks := readinessChecks(srlinux, featuresFor(srlinux.GetVersion()))
+	if checks == nil {
+		return 0
+	}
+
+	stages := []srlinuxv1.ReadinessStageStatus{{Name: srlinuxv1.ReadinessStageMgmtServer, Ready: true}}
+
+	driver := r.getNetworkDriver(ctx, log, pod)
+
+	var sessionErr error
+
+	ready := true
+
+	for _, c := range checks {
+		st, err := evaluateReadinessCheck(driver, c)
+		if err != nil {
+			sessionErr = err
+		}
+
+		ready = ready && st.Ready
+
+		stages = append(stages, st)
+	}
+
+	r.releaseNetworkDriver(log, driver, sessionErr)
+
+	if !cmp.Equal(srlinux.Status.Readiness, stages) || srlinux.Status.Ready != ready {
+		*update = true
+		srlinux.Status.Readiness = stages
+		srlinux.Status.Ready = ready
+	}
+
+	if !ready {
+		log.Info("SR Linux readiness stages are not yet passed, requeuing...", "stages", stages)
+
+		return readinessRetryInterval
+	}
+
+	return 0
+}
+
+// evaluateReadinessCheck evaluates the readiness check over the management session.
+// The error the command couldn't be sent over the session with is returned along with the stage status.
+func evaluateReadinessCheck(d *network.Driver, c readinessCheck) (srlinuxv1.ReadinessStageStatus, error) {
+	st := srlinuxv1.ReadinessStageStatus{Name: c.name}
+
+	if d == nil {
+		st.Message = "management session is not established"
+
+		return st, nil
+	}
+
+	r, err := d.SendCommand(c.cmd)
+	if err != nil {
+		st.Message = err.Error()
+
+		return st, err
+	}
+
+	if r.Failed != nil {
+		st.Message = r.Failed.Error()
+
+		return st, nil
+	}
+
+	got := parseLeafValue(r.Result, c.leaf)
+
+	switch got {
+	case c.want:
+		st.Ready = true
+	case "":
+		st.Message = fmt.Sprintf("%s is not reported", c.leaf)
+	default:
+		st.Message = fmt.Sprintf("%s is %s, expected %s", c.leaf, got, c.want)
+	}
+
+	return st, nil
+}
+
+// parseLeafValue extracts the value of the leaf from the output of `info from state` command,
+// e.g. `oper-state up` yields "up". Empty string is returned if the leaf is not found.
+func parseLeafValue(out, leaf string) string {
+	re := regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(leaf) + `\s+"?([^"\s]+)"?\s*$`)
+
+	m := re.FindStringSubmatch(out)
+	if m == nil {
+		return ""
+	}
+
+	return m[1]
+}
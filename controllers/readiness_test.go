@@ -0,0 +1,111 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+)
+
+func TestParseLeafValue(t *testing.T) {
+	tests := []struct {
+		desc string
+		out  string
+		leaf string
+		want string
+	}{
+		{
+			desc: "interface oper-state",
+			out: `    interface ethernet-1/1 {
+        oper-state up
+    }`,
+			leaf: "oper-state",
+			want: "up",
+		},
+		{
+			desc: "quoted value",
+			out: `    application bgp_mgr {
+        state "running"
+    }`,
+			leaf: "state",
+			want: "running",
+		},
+		{
+			desc: "leaf is not matched by a prefix",
+			out: `    neighbor 10.0.0.1 {
+        session-state active
+    }`,
+			leaf: "state",
+			want: "",
+		},
+		{
+			desc: "empty output",
+			leaf: "session-state",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := parseLeafValue(tt.out, tt.leaf); got != tt.want {
+				t.Fatalf("%s: expected %q, got %q", tt.desc, tt.want, got)
+			}
+		},
+		)
+	}
+}
+
+func TestReadinessChecks(t *testing.T) {
+	srl := &srlinuxv1.Srlinux{
+		Spec: srlinuxv1.SrlinuxSpec{
+			Readiness: &srlinuxv1.ReadinessSpec{
+				Apps:       []string{"bgp_mgr"},
+				Interfaces: []string{"ethernet-1/1"},
+				BGPNeighbors: []srlinuxv1.BGPNeighborReadiness{
+					{Address: "10.0.0.1"},
+					{NetworkInstance: "ip-vrf1", Address: "10.1.0.1"},
+				},
+			},
+		},
+	}
+
+	want := []readinessCheck{
+		{
+			name: "app/bgp_mgr",
+			cmd:  "info from state system app-management application bgp_mgr state",
+			leaf: "state",
+			want: "running",
+		},
+		{
+			name: "interface/ethernet-1/1",
+			cmd:  "info from state interface ethernet-1/1 oper-state",
+			leaf: "oper-state",
+			want: "up",
+		},
+		{
+			name: "bgp/default/10.0.0.1",
+			cmd:  "info from state network-instance default protocols bgp neighbor 10.0.0.1 session-state",
+			leaf: "session-state",
+			want: "established",
+		},
+		{
+			name: "bgp/ip-vrf1/10.1.0.1",
+			cmd:  "info from state network-instance ip-vrf1 protocols bgp neighbor 10.1.0.1 session-state",
+			leaf: "session-state",
+			want: "established",
+		},
+	}
+
+	got := readinessChecks(srl, srlFeatureTable[0])
+	if !cmp.Equal(got, want, cmp.AllowUnexported(readinessCheck{})) {
+		t.Fatalf("actual and expected checks do not match\n%s", cmp.Diff(want, got, cmp.AllowUnexported(readinessCheck{})))
+	}
+
+	if checks := readinessChecks(&srlinuxv1.Srlinux{}, srlFeatureTable[0]); checks != nil {
+		t.Fatalf("expected no checks without readiness spec, got %+v", checks)
+	}
+}
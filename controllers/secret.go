@@ -8,6 +8,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -18,20 +20,59 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
-var ErrLicenseProvisioning = errors.New("license provisioning failed")
+var (
+	ErrLicenseProvisioning  = errors.New("license provisioning failed")
+	ErrInvalidLicenseSource = errors.New("invalid license source")
+)
+
+// ParseLicenseSources parses a comma separated list of license secret references
+// in the form of `namespace/name` or `namespace` and returns them as namespaced names.
+// When the secret name is omitted, the default srlinux-licenses name is assumed.
+func ParseLicenseSources(s string) ([]types.NamespacedName, error) {
+	var srcs []types.NamespacedName
+
+	for _, ref := range strings.Split(s, ",") {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+
+		ns, name, found := strings.Cut(ref, "/")
+		if !found {
+			name = srlLicenseSecretName
+		}
+
+		if ns == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLicenseSource, ref)
+		}
 
-// createSecrets creates secrets such as srlinux-licenses.
+		srcs = append(srcs, types.NamespacedName{Namespace: ns, Name: name})
+	}
+
+	return srcs, nil
+}
+
+// createSecrets creates secrets such as srlinux-licenses, the default image pull secret
+// and the node TLS certificate.
 func (r *SrlinuxReconciler) createSecrets(
 	ctx context.Context,
 	s *srlinuxv1.Srlinux,
 	log logr.Logger,
 ) error {
+	if err := r.addOrUpdateImagePullSecret(ctx, s, log); err != nil {
+		return err
+	}
+
+	if err := r.createTLSSecret(ctx, s, log); err != nil {
+		return err
+	}
+
 	secret, err := r.addOrUpdateLicenseSecret(ctx, s, log)
 	if err != nil {
 		return err
 	}
 
-	v := s.Spec.GetImageVersion()
+	v := s.GetVersion()
 
 	if v.Major == "0" {
 		log.Info(
@@ -68,44 +109,42 @@ func (r *SrlinuxReconciler) addOrUpdateLicenseSecret(
 	return r.copyLicenseSecret(ctx, s, log)
 }
 
-// copyLicenseSecret finds a secret with srlinux licenses in srlinux-controller namespace
-// and copies it to the srlinux CR namespace and returns the pointer to the newly created secret.
-// If original secret doesn't exist (i.e. when no licenses were provisioned by a user)
+// copyLicenseSecret collects srlinux licenses from the license sources
+// and copies them to the srlinux CR namespace and returns the pointer to the newly created secret.
+// If none of the source secrets exist (i.e. when no licenses were provisioned by a user)
 // then nothing gets copied and nil returned.
 func (r *SrlinuxReconciler) copyLicenseSecret(
 	ctx context.Context,
 	s *srlinuxv1.Srlinux,
 	log logr.Logger,
 ) (*corev1.Secret, error) {
-	secret := &corev1.Secret{}
+	// collect licenses from the source secrets
+	// return silently if none found.
+	data, err := r.getLicenseSourceData(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// find license secret in controller' ns to copy from
-	// return silently if not found.
-	err := r.Get(
-		ctx,
-		types.NamespacedName{Name: srlLicenseSecretName, Namespace: controllerNamespace},
-		secret,
-	)
-	if err != nil && k8serrors.IsNotFound(err) {
+	if data == nil {
 		log.Info(
-			"secret with licenses is not found in controller's namespace, skipping copy to lab namespace",
+			"secret with licenses is not found in license sources, skipping copy to lab namespace",
 			"secret name",
 			srlLicenseSecretName,
-			"controller namespace",
-			controllerNamespace,
+			"license sources",
+			r.licenseSources(),
 		)
 
 		return nil, nil
 	}
 
-	// copy secret obj from controller's ns to a new secret
+	// copy licenses from the source secrets to a new secret
 	// that we put in the lab's ns
 	newSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      srlLicenseSecretName,
 			Namespace: s.Namespace,
 		},
-		Data: secret.Data,
+		Data: data,
 	}
 
 	log.Info("creating secret",
@@ -128,29 +167,29 @@ func (r *SrlinuxReconciler) updateLicenseSecret(
 	log logr.Logger,
 	secret *corev1.Secret,
 ) (*corev1.Secret, error) {
-	mainSecret := &corev1.Secret{} // Secret in controller' namespace we treat as a source of truth
-
-	// get license secret from controller' ns to copy from
-	// error if not found.
-	err := r.Get(
-		ctx,
-		types.NamespacedName{Name: srlLicenseSecretName, Namespace: controllerNamespace},
-		mainSecret,
-	)
-	if err != nil && k8serrors.IsNotFound(err) {
+	// licenses from the source secrets we treat as a source of truth
+	// error if none found.
+	data, err := r.getLicenseSourceData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
 		return nil, fmt.Errorf(
-			"%w: couldn't find Secret in controller's namespace",
+			"%w: couldn't find Secret in license sources",
 			ErrLicenseProvisioning,
 		)
 	}
 
 	// if secrets match, don't update the resource
-	if cmp.Equal(secret.Data, mainSecret.Data) {
+	if cmp.Equal(secret.Data, data) {
 		log.Info("secret already exists, not updating")
 
 		return secret, nil
 	}
 
+	secret.Data = data
+
 	log.Info("updating the secret")
 
 	err = r.Update(ctx, secret)
@@ -160,3 +199,115 @@ func (r *SrlinuxReconciler) updateLicenseSecret(
 
 	return secret, err
 }
+
+// licenseSources returns the list of secrets licenses are collected from.
+// The srlinux-licenses secret in the controller's namespace always comes first,
+// followed by the additional license sources in the order they were provided.
+func (r *SrlinuxReconciler) licenseSources() []types.NamespacedName {
+	srcs := []types.NamespacedName{
+		{Name: srlLicenseSecretName, Namespace: r.controllerNamespace()},
+	}
+
+	for _, src := range r.LicenseSources {
+		if !slices.Contains(srcs, src) {
+			srcs = append(srcs, src)
+		}
+	}
+
+	return srcs
+}
+
+// getLicenseSourceData merges the data of all license source secrets.
+// When the same key is present in several sources, the key from the source listed first wins.
+// Missing sources are skipped, and nil is returned when none of the sources exist.
+func (r *SrlinuxReconciler) getLicenseSourceData(ctx context.Context) (map[string][]byte, error) {
+	var data map[string][]byte
+
+	for _, src := range r.licenseSources() {
+		secret := &corev1.Secret{}
+
+		err := r.Get(ctx, src, secret)
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if data == nil {
+			data = map[string][]byte{}
+		}
+
+		for k, v := range secret.Data {
+			if _, ok := data[k]; !ok {
+				data[k] = v
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// addOrUpdateImagePullSecret copies the default image pull secret from the controller's namespace
+// to the srlinux CR namespace the same way srlinux-licenses secret is copied.
+// The secret in the controller's namespace is treated as a source of truth, and the copy is updated
+// when the data differs. If the default image pull secret is not set or doesn't exist, nothing is copied.
+func (r *SrlinuxReconciler) addOrUpdateImagePullSecret(
+	ctx context.Context,
+	s *srlinuxv1.Srlinux,
+	log logr.Logger,
+) error {
+	if r.ImagePullSecret == "" {
+		return nil
+	}
+
+	mainSecret := &corev1.Secret{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: r.ImagePullSecret, Namespace: r.controllerNamespace()}, mainSecret)
+	if k8serrors.IsNotFound(err) {
+		log.Info(
+			"image pull secret is not found in controller's namespace, skipping copy to lab namespace",
+			"secret name",
+			r.ImagePullSecret,
+			"controller namespace",
+			r.controllerNamespace(),
+		)
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+
+	err = r.Get(ctx, types.NamespacedName{Name: r.ImagePullSecret, Namespace: s.Namespace}, secret)
+	if k8serrors.IsNotFound(err) {
+		log.Info("creating image pull secret", "secret name", r.ImagePullSecret)
+
+		return r.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      r.ImagePullSecret,
+				Namespace: s.Namespace,
+			},
+			Type: mainSecret.Type,
+			Data: mainSecret.Data,
+		})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if cmp.Equal(secret.Data, mainSecret.Data) {
+		return nil
+	}
+
+	log.Info("updating image pull secret", "secret name", r.ImagePullSecret)
+
+	secret.Data = mainSecret.Data
+
+	return r.Update(ctx, secret)
+}
@@ -0,0 +1,264 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseLicenseSources(t *testing.T) {
+	tests := []struct {
+		desc string
+		got  string
+		want []types.NamespacedName
+		err  error
+	}{
+		{
+			desc: "empty",
+			got:  "",
+			want: nil,
+		},
+		{
+			desc: "namespace and name",
+			got:  "lab-licenses/srl",
+			want: []types.NamespacedName{{Namespace: "lab-licenses", Name: "srl"}},
+		},
+		{
+			desc: "namespace only, default name applies",
+			got:  "lab-licenses",
+			want: []types.NamespacedName{{Namespace: "lab-licenses", Name: srlLicenseSecretName}},
+		},
+		{
+			desc: "multiple sources with spaces",
+			got:  "ns1/a, ns2 ,",
+			want: []types.NamespacedName{
+				{Namespace: "ns1", Name: "a"},
+				{Namespace: "ns2", Name: srlLicenseSecretName},
+			},
+		},
+		{
+			desc: "missing namespace",
+			got:  "/srl",
+			err:  ErrInvalidLicenseSource,
+		},
+		{
+			desc: "too many separators",
+			got:  "ns/a/b",
+			err:  ErrInvalidLicenseSource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			srcs, err := ParseLicenseSources(tt.got)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if !cmp.Equal(srcs, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					srcs,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
+
+func TestGetLicenseSourceData(t *testing.T) {
+	newSecret := func(ns, name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		desc       string
+		ctrlNs     string
+		sources    []types.NamespacedName
+		clientObjs []runtime.Object
+		want       map[string][]byte
+	}{
+		{
+			desc: "no license secrets",
+			want: nil,
+		},
+		{
+			desc: "secret in default controller namespace",
+			clientObjs: []runtime.Object{
+				newSecret(DefaultControllerNamespace, srlLicenseSecretName,
+					map[string][]byte{"all.key": []byte("a")}),
+			},
+			want: map[string][]byte{"all.key": []byte("a")},
+		},
+		{
+			desc:   "secret in custom controller namespace",
+			ctrlNs: "kne-system",
+			clientObjs: []runtime.Object{
+				newSecret(DefaultControllerNamespace, srlLicenseSecretName,
+					map[string][]byte{"all.key": []byte("a")}),
+				newSecret("kne-system", srlLicenseSecretName,
+					map[string][]byte{"all.key": []byte("b")}),
+			},
+			want: map[string][]byte{"all.key": []byte("b")},
+		},
+		{
+			desc: "licenses merged from additional sources, first source wins",
+			sources: []types.NamespacedName{
+				{Namespace: "team-a", Name: "srl"},
+				{Namespace: "team-b", Name: "missing"},
+			},
+			clientObjs: []runtime.Object{
+				newSecret(DefaultControllerNamespace, srlLicenseSecretName,
+					map[string][]byte{"all.key": []byte("a")}),
+				newSecret("team-a", "srl",
+					map[string][]byte{"all.key": []byte("b"), "23-10.key": []byte("c")}),
+			},
+			want: map[string][]byte{"all.key": []byte("a"), "23-10.key": []byte("c")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &SrlinuxReconciler{
+				Client:              fake.NewClientBuilder().WithRuntimeObjects(tt.clientObjs...).Build(),
+				Scheme:              scheme.Scheme,
+				ControllerNamespace: tt.ctrlNs,
+				LicenseSources:      tt.sources,
+			}
+
+			data, err := r.getLicenseSourceData(ctx)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if !cmp.Equal(data, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					data,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
+
+func TestAddOrUpdateImagePullSecret(t *testing.T) {
+	pullSecretName := "regcred"
+	dockerCfg := map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)}
+
+	tests := []struct {
+		desc       string
+		clientObjs []runtime.Object
+		want       *corev1.Secret
+	}{
+		{
+			desc: "default pull secret does not exist",
+			want: nil,
+		},
+		{
+			desc: "default pull secret copied to lab namespace",
+			clientObjs: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: pullSecretName, Namespace: DefaultControllerNamespace},
+					Type:       corev1.SecretTypeDockerConfigJson,
+					Data:       dockerCfg,
+				},
+			},
+			want: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: dockerCfg,
+			},
+		},
+		{
+			desc: "outdated pull secret updated in lab namespace",
+			clientObjs: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: pullSecretName, Namespace: DefaultControllerNamespace},
+					Type:       corev1.SecretTypeDockerConfigJson,
+					Data:       dockerCfg,
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: pullSecretName, Namespace: defaultNamespace},
+					Type:       corev1.SecretTypeDockerConfigJson,
+					Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{}`)},
+				},
+			},
+			want: &corev1.Secret{
+				Type: corev1.SecretTypeDockerConfigJson,
+				Data: dockerCfg,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithRuntimeObjects(tt.clientObjs...).Build()
+			r := &SrlinuxReconciler{
+				Client:          c,
+				Scheme:          scheme.Scheme,
+				ImagePullSecret: pullSecretName,
+			}
+
+			srl := &srlinuxv1.Srlinux{ObjectMeta: metav1.ObjectMeta{Name: defaultCRName, Namespace: defaultNamespace}}
+
+			if err := r.addOrUpdateImagePullSecret(ctx, srl, logr.Discard()); err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			// the pod references the default pull secret only when it was copied
+			refs := r.createImagePullSecrets(ctx, srl)
+
+			referenced := slices.Contains(refs, corev1.LocalObjectReference{Name: pullSecretName})
+			if referenced != (tt.want != nil) {
+				t.Fatalf("%s: unexpected image pull secrets of the pod: %+v", tt.desc, refs)
+			}
+
+			secret := &corev1.Secret{}
+
+			err := c.Get(ctx, types.NamespacedName{Name: pullSecretName, Namespace: defaultNamespace}, secret)
+			if tt.want == nil {
+				if !k8serrors.IsNotFound(err) {
+					t.Fatalf("%s: expected secret to be absent, got error: %v", tt.desc, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if !cmp.Equal(secret.Data, tt.want.Data) || secret.Type != tt.want.Type {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					secret,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
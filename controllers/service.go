@@ -0,0 +1,356 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// managedAnnotationsAnnotation lists the Service annotations set from the spec,
+// so that the annotations removed from the spec are removed from the Service.
+const managedAnnotationsAnnotation = "kne.srlinux.dev/managed-annotations"
+
+const (
+	endpointDialTimeout = time.Second
+	// endpointProbeInterval is the interval the management endpoints are probed at
+	// while some of the endpoints are not reachable, e.g. not started yet.
+	endpointProbeInterval = 30 * time.Second
+	// endpointRefreshInterval is the interval the management endpoints are probed at once all are reachable.
+	endpointRefreshInterval = 5 * time.Minute
+)
+
+// serviceName returns the name of the management Service of a Srlinux node.
+func serviceName(s *srlinuxv1.Srlinux) string {
+	return fmt.Sprintf("%s-mgmt", s.Name)
+}
+
+// handleSrlinuxService creates the Service exposing management endpoints of a node
+// when it is requested in the spec and keeps it in sync with the spec.
+// The Service is deleted when it is no longer requested. The Service addresses are recorded in the status.
+func (r *SrlinuxReconciler) handleSrlinuxService(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+) error {
+	svc := &corev1.Service{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: serviceName(srlinux), Namespace: srlinux.Namespace}, svc)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		log.Error(err, "failed to get Service")
+
+		return err
+	}
+
+	exists := err == nil
+
+	if srlinux.Spec.Service == nil {
+		return r.deleteSrlinuxService(ctx, log, update, srlinux, svc, exists)
+	}
+
+	desired, err := r.serviceForSrlinux(srlinux)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !exists:
+		log.Info("creating a new service", "service", desired.Name)
+
+		if err := r.Create(ctx, desired); err != nil {
+			log.Error(err, "failed to create new Service")
+
+			return err
+		}
+
+		svc = desired
+	case !serviceInSync(svc, desired):
+		log.Info("updating the service", "service", svc.Name)
+
+		syncService(svc, desired)
+
+		if err := r.Update(ctx, svc); err != nil {
+			log.Error(err, "failed to update Service")
+
+			return err
+		}
+	}
+
+	mgmt := &srlinux.Status.Management
+	externalIP := serviceExternalIP(svc)
+
+	if mgmt.Service != svc.Name || mgmt.ServiceIP != svc.Spec.ClusterIP || mgmt.ExternalIP != externalIP {
+		*update = true
+		mgmt.Service = svc.Name
+		mgmt.ServiceIP = svc.Spec.ClusterIP
+		mgmt.ExternalIP = externalIP
+	}
+
+	return nil
+}
+
+// deleteSrlinuxService deletes the management Service that is no longer requested in the spec.
+// Only the Service owned by the Srlinux object is deleted.
+func (r *SrlinuxReconciler) deleteSrlinuxService(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	svc *corev1.Service,
+	exists bool,
+) error {
+	if exists && metav1.IsControlledBy(svc, srlinux) {
+		log.Info("deleting the service", "service", svc.Name)
+
+		if err := r.Delete(ctx, svc); err != nil && !k8serrors.IsNotFound(err) {
+			log.Error(err, "failed to delete Service")
+
+			return err
+		}
+	}
+
+	mgmt := &srlinux.Status.Management
+	if mgmt.Service != "" || mgmt.ServiceIP != "" || mgmt.ExternalIP != "" {
+		*update = true
+		mgmt.Service = ""
+		mgmt.ServiceIP = ""
+		mgmt.ExternalIP = ""
+	}
+
+	return nil
+}
+
+// serviceInSync checks if the Service matches the desired type, ports, labels and annotations.
+func serviceInSync(svc, desired *corev1.Service) bool {
+	if svc.Spec.Type != desired.Spec.Type || len(svc.Spec.Ports) != len(desired.Spec.Ports) {
+		return false
+	}
+
+	for i, p := range desired.Spec.Ports {
+		sp := svc.Spec.Ports[i]
+		if sp.Name != p.Name || sp.Port != p.Port || sp.Protocol != p.Protocol || sp.TargetPort != p.TargetPort {
+			return false
+		}
+	}
+
+	for k, v := range desired.Labels {
+		if svc.Labels[k] != v {
+			return false
+		}
+	}
+
+	for k, v := range desired.Annotations {
+		if svc.Annotations[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// syncService updates the Service with the desired type, ports, labels and annotations.
+// Node ports allocated to the Service are preserved when the Service type doesn't change.
+func syncService(svc, desired *corev1.Service) {
+	if svc.Spec.Type == desired.Spec.Type {
+		for i, p := range desired.Spec.Ports {
+			for _, sp := range svc.Spec.Ports {
+				if sp.Port == p.Port {
+					desired.Spec.Ports[i].NodePort = sp.NodePort
+				}
+			}
+		}
+	}
+
+	svc.Spec.Type = desired.Spec.Type
+	svc.Spec.Ports = desired.Spec.Ports
+
+	if svc.Labels == nil {
+		svc.Labels = map[string]string{}
+	}
+
+	for k, v := range desired.Labels {
+		svc.Labels[k] = v
+	}
+
+	if svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+
+	// annotations set from the spec before and removed from it since are removed,
+	// annotations set by others (e.g. a load balancer) are kept
+	for _, k := range managedAnnotations(svc) {
+		if _, ok := desired.Annotations[k]; !ok {
+			delete(svc.Annotations, k)
+		}
+	}
+
+	for k, v := range desired.Annotations {
+		svc.Annotations[k] = v
+	}
+}
+
+// managedAnnotations returns the keys of the Service annotations set from the spec.
+func managedAnnotations(svc *corev1.Service) []string {
+	v := svc.Annotations[managedAnnotationsAnnotation]
+	if v == "" {
+		return nil
+	}
+
+	return strings.Split(v, ",")
+}
+
+// serviceExternalIP returns the address assigned to a LoadBalancer Service.
+func serviceExternalIP(svc *corev1.Service) string {
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return ""
+	}
+
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		if ing.IP != "" {
+			return ing.IP
+		}
+
+		if ing.Hostname != "" {
+			return ing.Hostname
+		}
+	}
+
+	return ""
+}
+
+// serviceForSrlinux returns a Service object exposing management endpoints of a node.
+func (r *SrlinuxReconciler) serviceForSrlinux(s *srlinuxv1.Srlinux) (*corev1.Service, error) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName(s),
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				"app":  s.Name,
+				"topo": s.Namespace,
+			},
+			Annotations: maps.Clone(s.Spec.Service.Annotations),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     s.Spec.Service.GetServiceType(),
+			Selector: map[string]string{"app": s.Name},
+			Ports:    createServicePorts(s),
+		},
+	}
+
+	if svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+
+	svc.Annotations[managedAnnotationsAnnotation] = strings.Join(slices.Sorted(maps.Keys(s.Spec.Service.Annotations)), ",")
+
+	if err := ctrl.SetControllerReference(s, svc, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// createServicePorts creates service ports for the management ports configured for the Service.
+// Ports listed more than once (e.g. gNMI and gNOI) are exposed with a single service port.
+func createServicePorts(s *srlinuxv1.Srlinux) []corev1.ServicePort {
+	var ports []corev1.ServicePort
+
+	for _, ep := range s.Spec.Service.GetPorts() {
+		if slices.ContainsFunc(ports, func(p corev1.ServicePort) bool { return p.Port == ep.Port }) {
+			continue
+		}
+
+		ports = append(ports, corev1.ServicePort{
+			Name:       ep.Name,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       ep.Port,
+			TargetPort: intstr.FromInt32(ep.Port),
+		})
+	}
+
+	return ports
+}
+
+// handleSrlinuxEndpoints discovers management endpoints listening on the node management IP
+// and records them in the status with the time of the probe. The endpoints are probed again
+// after endpointProbeInterval while some are not reachable, and after endpointRefreshInterval otherwise.
+// The time until the next probe is returned.
+func (*SrlinuxReconciler) handleSrlinuxEndpoints(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	now time.Time,
+) time.Duration {
+	ip := srlinux.Status.Management.IP
+	if ip == "" {
+		return 0
+	}
+
+	all := srlinux.Spec.GetManagementEndpoints()
+
+	interval := endpointProbeInterval
+	if len(srlinux.Status.Management.Endpoints) == len(all) {
+		interval = endpointRefreshInterval
+	}
+
+	if t := srlinux.Status.Management.EndpointsProbeTime; t != nil {
+		if wait := t.Add(interval).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	var eps []srlinuxv1.ManagementEndpoint
+
+	for _, ep := range all {
+		if endpointReachable(ctx, ip, ep.Port) {
+			eps = append(eps, ep)
+		}
+	}
+
+	if !slices.Equal(eps, srlinux.Status.Management.Endpoints) {
+		log.Info("management endpoints discovered", "endpoints", eps)
+	}
+
+	srlinux.Status.Management.Endpoints = eps
+	srlinux.Status.Management.EndpointsProbeTime = &metav1.Time{Time: now}
+	*update = true
+
+	if len(eps) == len(all) {
+		return endpointRefreshInterval
+	}
+
+	return endpointProbeInterval
+}
+
+// endpointReachable checks if a TCP connection can be established to the given IP and port.
+func endpointReachable(ctx context.Context, ip string, port int32) bool {
+	d := net.Dialer{Timeout: endpointDialTimeout}
+
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(int(port))))
+	if err != nil {
+		return false
+	}
+
+	_ = conn.Close()
+
+	return true
+}
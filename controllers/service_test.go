@@ -0,0 +1,79 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestHandleSrlinuxEndpoints(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	all := (&srlinuxv1.SrlinuxSpec{}).GetManagementEndpoints()
+
+	tests := []struct {
+		desc      string
+		status    srlinuxv1.ManagementStatus
+		wantWait  time.Duration
+		wantProbe bool
+	}{
+		{
+			desc:     "no management IP",
+			wantWait: 0,
+		},
+		{
+			desc:      "never probed",
+			status:    srlinuxv1.ManagementStatus{IP: "127.0.0.1"},
+			wantWait:  endpointProbeInterval,
+			wantProbe: true,
+		},
+		{
+			desc: "endpoints missing, probed recently",
+			status: srlinuxv1.ManagementStatus{
+				IP:                 "127.0.0.1",
+				EndpointsProbeTime: &metav1.Time{Time: now.Add(-10 * time.Second)},
+			},
+			wantWait: endpointProbeInterval - 10*time.Second,
+		},
+		{
+			desc: "endpoints missing, probe interval passed",
+			status: srlinuxv1.ManagementStatus{
+				IP:                 "127.0.0.1",
+				EndpointsProbeTime: &metav1.Time{Time: now.Add(-endpointProbeInterval)},
+			},
+			wantWait:  endpointProbeInterval,
+			wantProbe: true,
+		},
+		{
+			desc: "all endpoints found, refreshed later",
+			status: srlinuxv1.ManagementStatus{
+				IP:                 "127.0.0.1",
+				Endpoints:          all,
+				EndpointsProbeTime: &metav1.Time{Time: now.Add(-endpointProbeInterval)},
+			},
+			wantWait: endpointRefreshInterval - endpointProbeInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			update := false
+			srl := &srlinuxv1.Srlinux{Status: srlinuxv1.SrlinuxStatus{Management: tt.status}}
+
+			wait := (&SrlinuxReconciler{}).handleSrlinuxEndpoints(ctx, ctrl.Log, &update, srl, now)
+
+			probed := update && srl.Status.Management.EndpointsProbeTime.Equal(&metav1.Time{Time: now})
+			if wait != tt.wantWait || probed != tt.wantProbe {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %v %v\nexpected:%v %v",
+					tt.desc, wait, probed, tt.wantWait, tt.wantProbe)
+			}
+		},
+		)
+	}
+}
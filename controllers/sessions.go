@@ -0,0 +1,327 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/scrapli/scrapligo/driver/network"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// maxSessionsPerNode limits the number of concurrent management sessions to a node.
+	maxSessionsPerNode = 2
+	// sessionAcquireTimeout is the time waited for a session slot of a node to become available.
+	sessionAcquireTimeout = 30 * time.Second
+	// sessionKeepaliveInterval is the interval idle sessions are kept alive and checked at.
+	sessionKeepaliveInterval = 30 * time.Second
+	// sessionIdleTimeout is the time after which an idle session is closed.
+	// It is longer than the intervals the nodes are periodically looked at, e.g. the default drift check
+	// and the management endpoints refresh intervals, so that the sessions are reused between the checks.
+	sessionIdleTimeout = 15 * time.Minute
+)
+
+// SessionPool keeps the management sessions to the SR Linux nodes open for reuse, keyed by the pod UID,
+// so that the node handlers don't open a new SSH session on every reconciliation.
+// A session is taken from the pool with acquire and returned to it with release.
+// A nil pool opens a new session on every acquire and closes it on release.
+// The pool is shared by the controllers and run by the manager to keep the idle sessions alive.
+type SessionPool struct {
+	mu sync.Mutex
+	// nodes are the sessions of the nodes keyed by the pod UID.
+	nodes map[types.UID]*nodeSessions
+	// inUse maps the acquired drivers to the sessions of their nodes.
+	inUse map[*network.Driver]*nodeSessions
+
+	// open opens a new session to the node with the management IP.
+	open func(log logr.Logger, ip string) *network.Driver
+	// alive checks the session is alive, exchanging data over it.
+	alive func(d *network.Driver) bool
+	// close closes the session.
+	close func(d *network.Driver) error
+}
+
+// nodeSessions are the sessions of a node.
+type nodeSessions struct {
+	node types.NamespacedName
+	uid  types.UID
+	ip   string
+	idle []idleSession
+	// slots limits the number of the sessions of the node in use.
+	slots chan struct{}
+}
+
+// idleSession is a session in the pool not in use.
+type idleSession struct {
+	d     *network.Driver
+	since time.Time
+}
+
+// NewSessionPool creates a pool of the sessions opened with the network driver.
+func NewSessionPool() *SessionPool {
+	return &SessionPool{
+		nodes: map[types.UID]*nodeSessions{},
+		inUse: map[*network.Driver]*nodeSessions{},
+		open:  openNetworkDriver,
+		alive: func(d *network.Driver) bool {
+			_, err := d.GetPrompt()
+
+			return err == nil
+		},
+		close: func(d *network.Driver) error { return d.Close() },
+	}
+}
+
+// acquire returns a session to the node running in the pod with the given UID and management IP.
+// An idle session is reused if it is alive, otherwise a new session is opened.
+// Nil is returned if a session couldn't be opened or the node has no session slot available in time.
+func (p *SessionPool) acquire(
+	ctx context.Context,
+	log logr.Logger,
+	node types.NamespacedName,
+	uid types.UID,
+	ip string,
+) *network.Driver {
+	if p == nil {
+		return openNetworkDriver(log, ip)
+	}
+
+	p.mu.Lock()
+
+	ns := p.nodes[uid]
+	if ns == nil {
+		ns = &nodeSessions{node: node, uid: uid, ip: ip, slots: make(chan struct{}, maxSessionsPerNode)}
+		p.nodes[uid] = ns
+	}
+
+	// the sessions to the previous IP of the pod are stale
+	var stale []idleSession
+	if ns.ip != ip {
+		stale = p.takeIdle(ns)
+		ns.ip = ip
+	}
+
+	p.mu.Unlock()
+
+	p.closeAll(log, stale)
+
+	select {
+	case ns.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil
+	case <-time.After(sessionAcquireTimeout):
+		log.Info("no management session available for the node", "in use", maxSessionsPerNode)
+
+		return nil
+	}
+
+	for {
+		p.mu.Lock()
+
+		var d *network.Driver
+		if n := len(ns.idle); n > 0 {
+			d, ns.idle = ns.idle[n-1].d, ns.idle[:n-1]
+		}
+
+		p.mu.Unlock()
+
+		if d == nil {
+			break
+		}
+
+		if p.alive(d) {
+			p.mu.Lock()
+			p.inUse[d] = ns
+			p.mu.Unlock()
+
+			return d
+		}
+
+		log.Info("idle management session is not alive, reconnecting")
+
+		if err := p.close(d); err != nil {
+			log.Error(err, "failed to close driver")
+		}
+	}
+
+	d := p.open(log, ip)
+	if d == nil {
+		<-ns.slots
+
+		return nil
+	}
+
+	p.mu.Lock()
+	p.inUse[d] = ns
+	p.mu.Unlock()
+
+	return d
+}
+
+// release returns the acquired session to the pool.
+// The session is closed if it failed with the given error, e.g. a command timed out,
+// or if the node's sessions were closed while it was in use.
+func (p *SessionPool) release(log logr.Logger, d *network.Driver, err error) {
+	if d == nil {
+		return
+	}
+
+	if p == nil {
+		if err := d.Close(); err != nil {
+			log.Error(err, "failed to close driver")
+		}
+
+		return
+	}
+
+	p.mu.Lock()
+
+	ns, ok := p.inUse[d]
+	delete(p.inUse, d)
+
+	open := ok && p.nodes[ns.uid] == ns && err == nil
+	if open {
+		ns.idle = append(ns.idle, idleSession{d: d, since: time.Now()})
+	}
+
+	p.mu.Unlock()
+
+	if !open {
+		if err != nil {
+			log.Info("closing the management session that failed", "error", err.Error())
+		}
+
+		if err := p.close(d); err != nil {
+			log.Error(err, "failed to close driver")
+		}
+	}
+
+	if ok {
+		<-ns.slots
+	}
+}
+
+// closeStale closes the sessions of the node opened to the pods other than the one with the given UID,
+// e.g. when the pod is re-created. All sessions of the node are closed when the UID is empty.
+func (p *SessionPool) closeStale(log logr.Logger, node types.NamespacedName, uid types.UID) {
+	if p == nil {
+		return
+	}
+
+	var stale []idleSession
+
+	p.mu.Lock()
+
+	for u, ns := range p.nodes {
+		if ns.node != node || u == uid {
+			continue
+		}
+
+		log.Info("closing management sessions of the gone pod", "pod uid", u)
+
+		stale = append(stale, p.takeIdle(ns)...)
+		delete(p.nodes, u)
+	}
+
+	p.mu.Unlock()
+
+	p.closeAll(log, stale)
+}
+
+// Start keeps the idle sessions alive and closes the sessions idle for too long or found dead
+// until the context is done, then it closes all sessions.
+func (p *SessionPool) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("sessions")
+
+	tick := time.NewTicker(sessionKeepaliveInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			var idle []idleSession
+
+			p.mu.Lock()
+			for u, ns := range p.nodes {
+				idle = append(idle, p.takeIdle(ns)...)
+				delete(p.nodes, u)
+			}
+			p.mu.Unlock()
+
+			p.closeAll(log, idle)
+
+			return nil
+		case <-tick.C:
+			p.keepalive(log, time.Now())
+		}
+	}
+}
+
+// keepalive exchanges data over the idle sessions to keep them alive
+// and closes the sessions found dead or idle for too long.
+// The sessions reaching the idle timeout before the next keepalive are closed without being kept alive.
+func (p *SessionPool) keepalive(log logr.Logger, now time.Time) {
+	p.mu.Lock()
+
+	checked := map[*nodeSessions][]idleSession{}
+
+	for _, ns := range p.nodes {
+		checked[ns], ns.idle = ns.idle, nil
+	}
+
+	p.mu.Unlock()
+
+	for ns, idle := range checked {
+		var keep []idleSession
+
+		for _, s := range idle {
+			if now.Sub(s.since)+sessionKeepaliveInterval < sessionIdleTimeout && p.alive(s.d) {
+				keep = append(keep, s)
+
+				continue
+			}
+
+			if err := p.close(s.d); err != nil {
+				log.Error(err, "failed to close driver")
+			}
+		}
+
+		p.mu.Lock()
+
+		gone := p.nodes[ns.uid] != ns
+		if !gone {
+			ns.idle = append(ns.idle, keep...)
+		}
+
+		p.mu.Unlock()
+
+		if gone {
+			p.closeAll(log, keep)
+		}
+	}
+}
+
+// takeIdle removes the idle sessions of the node from the pool and returns them to be closed.
+// The caller holds the lock.
+func (*SessionPool) takeIdle(ns *nodeSessions) []idleSession {
+	idle := ns.idle
+	ns.idle = nil
+
+	return idle
+}
+
+// closeAll closes the sessions. The caller doesn't hold the lock, closing a session may block.
+func (p *SessionPool) closeAll(log logr.Logger, sessions []idleSession) {
+	for _, s := range sessions {
+		if err := p.close(s.d); err != nil {
+			log.Error(err, "failed to close driver")
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/scrapli/scrapligo/driver/network"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// fakeSessions records the sessions opened and closed by the pool.
+type fakeSessions struct {
+	opened int
+	pinged int
+	closed map[*network.Driver]bool
+	dead   map[*network.Driver]bool
+	// closedLocked counts the sessions closed while the pool lock was held.
+	closedLocked int
+}
+
+func newFakeSessionPool() (*SessionPool, *fakeSessions) {
+	f := &fakeSessions{closed: map[*network.Driver]bool{}, dead: map[*network.Driver]bool{}}
+
+	p := NewSessionPool()
+	p.open = func(logr.Logger, string) *network.Driver {
+		f.opened++
+
+		return &network.Driver{}
+	}
+	p.alive = func(d *network.Driver) bool {
+		f.pinged++
+
+		return !f.dead[d]
+	}
+	p.close = func(d *network.Driver) error {
+		f.closed[d] = true
+
+		if !p.mu.TryLock() {
+			f.closedLocked++
+		} else {
+			p.mu.Unlock()
+		}
+
+		return nil
+	}
+
+	return p, f
+}
+
+func TestSessionPool(t *testing.T) {
+	node := types.NamespacedName{Name: defaultCRName, Namespace: defaultNamespace}
+
+	tests := []struct {
+		desc   string
+		testFn func(t *testing.T, p *SessionPool, f *fakeSessions)
+	}{
+		{
+			desc: "released session is reused",
+			testFn: func(t *testing.T, p *SessionPool, f *fakeSessions) {
+				d := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1")
+				p.release(ctrl.Log, d, nil)
+
+				if reused := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1"); reused != d || f.opened != 1 {
+					t.Fatalf("expected the session to be reused, opened %d sessions", f.opened)
+				}
+			},
+		},
+		{
+			desc: "dead session is reconnected",
+			testFn: func(t *testing.T, p *SessionPool, f *fakeSessions) {
+				d := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1")
+				p.release(ctrl.Log, d, nil)
+
+				f.dead[d] = true
+
+				if n := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1"); n == d || !f.closed[d] || f.opened != 2 {
+					t.Fatalf("expected the dead session to be closed and a new one opened, opened %d sessions", f.opened)
+				}
+			},
+		},
+		{
+			desc: "concurrent sessions per node are limited",
+			testFn: func(t *testing.T, p *SessionPool, f *fakeSessions) {
+				for range maxSessionsPerNode {
+					if p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1") == nil {
+						t.Fatalf("expected a session to be acquired")
+					}
+				}
+
+				tctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				defer cancel()
+
+				if d := p.acquire(tctx, ctrl.Log, node, "uid1", "10.0.0.1"); d != nil {
+					t.Fatalf("expected no session to be acquired over the limit")
+				}
+
+				if d := p.acquire(ctx, ctrl.Log, types.NamespacedName{Name: "other"}, "uid2", "10.0.0.2"); d == nil {
+					t.Fatalf("expected a session to another node to be acquired")
+				}
+			},
+		},
+		{
+			desc: "sessions to the re-created pod are closed",
+			testFn: func(t *testing.T, p *SessionPool, f *fakeSessions) {
+				idle := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1")
+				inUse := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1")
+				p.release(ctrl.Log, idle, nil)
+
+				p.closeStale(ctrl.Log, node, "uid2")
+
+				if !f.closed[idle] || f.closed[inUse] {
+					t.Fatalf("expected only the idle session to be closed right away")
+				}
+
+				p.release(ctrl.Log, inUse, nil)
+
+				if !f.closed[inUse] {
+					t.Fatalf("expected the session in use to be closed on release")
+				}
+
+				if d := p.acquire(ctx, ctrl.Log, node, "uid2", "10.0.0.3"); d == idle || d == inUse {
+					t.Fatalf("expected a new session to the new pod")
+				}
+			},
+		},
+		{
+			desc: "sessions idle for too long are closed",
+			testFn: func(t *testing.T, p *SessionPool, f *fakeSessions) {
+				d := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1")
+				p.release(ctrl.Log, d, nil)
+
+				p.keepalive(ctrl.Log, time.Now())
+
+				if f.closed[d] {
+					t.Fatalf("expected the live session to be kept")
+				}
+
+				pinged := f.pinged
+
+				// the session would reach the idle timeout before the next keepalive
+				p.keepalive(ctrl.Log, time.Now().Add(sessionIdleTimeout-sessionKeepaliveInterval/2))
+
+				if !f.closed[d] || f.pinged != pinged {
+					t.Fatalf("expected the idle session to be closed without being kept alive, pinged %d times", f.pinged)
+				}
+			},
+		},
+		{
+			desc: "sessions to the previous pod IP are closed",
+			testFn: func(t *testing.T, p *SessionPool, f *fakeSessions) {
+				d := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1")
+				p.release(ctrl.Log, d, nil)
+
+				if n := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.2"); n == d || !f.closed[d] {
+					t.Fatalf("expected the session to the previous IP to be closed and a new session opened")
+				}
+			},
+		},
+		{
+			desc: "sessions closed when the pool stops",
+			testFn: func(t *testing.T, p *SessionPool, f *fakeSessions) {
+				d := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1")
+				p.release(ctrl.Log, d, nil)
+
+				stopped, cancel := context.WithCancel(ctx)
+				cancel()
+
+				if err := p.Start(stopped); err != nil || !f.closed[d] {
+					t.Fatalf("expected the idle session to be closed, error: %v", err)
+				}
+			},
+		},
+		{
+			desc: "session failed in use is closed on release",
+			testFn: func(t *testing.T, p *SessionPool, f *fakeSessions) {
+				d := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1")
+				p.release(ctrl.Log, d, errors.New("timed out sending command"))
+
+				if !f.closed[d] {
+					t.Fatalf("expected the failed session to be closed")
+				}
+
+				if n := p.acquire(ctx, ctrl.Log, node, "uid1", "10.0.0.1"); n == d || f.opened != 2 {
+					t.Fatalf("expected a new session to be opened, opened %d sessions", f.opened)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			p, f := newFakeSessionPool()
+
+			tt.testFn(t, p, f)
+
+			if f.closedLocked > 0 {
+				t.Fatalf("expected the sessions to be closed outside the pool lock, %d closed under it", f.closedLocked)
+			}
+		},
+		)
+	}
+}
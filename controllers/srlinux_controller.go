@@ -33,9 +33,12 @@ package controllers
 import (
 	"context"
 	"embed"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -43,11 +46,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
 	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
 )
 
 const (
-	controllerNamespace = "srlinux-controller"
+	// DefaultControllerNamespace is the namespace the controller is deployed to by default
+	// and is used when the namespace is not explicitly set for the reconciler.
+	DefaultControllerNamespace = "srlinux-controller"
 
 	variantsVolName          = "variants"
 	variantsVolMntPath       = "/tmp/topo"
@@ -77,6 +83,31 @@ var variantsFS embed.FS
 type SrlinuxReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// ControllerNamespace is the namespace the controller runs in.
+	// The srlinux-licenses secret is looked up in this namespace.
+	ControllerNamespace string
+	// LicenseSources is a list of additional secrets srlinux licenses are collected from.
+	LicenseSources []types.NamespacedName
+	// ImagePullSecret is a name of the image pull secret in the controller's namespace
+	// that is copied to Srlinux namespaces and used by every srlinux pod.
+	ImagePullSecret string
+	// StartupConfigURLHosts are the hosts the startup config URL sources may be fetched from.
+	// URL sources are refused when no hosts are allowed.
+	StartupConfigURLHosts []string
+
+	// Sessions keeps the management sessions to the nodes open for reuse across reconciliations.
+	// Without the pool a session is opened for every use.
+	Sessions *SessionPool
+}
+
+// controllerNamespace returns the namespace the controller runs in,
+// falling back to the default controller namespace when it is not set.
+func (r *SrlinuxReconciler) controllerNamespace() string {
+	if r.ControllerNamespace != "" {
+		return r.ControllerNamespace
+	}
+
+	return DefaultControllerNamespace
 }
 
 //+kubebuilder:rbac:groups=kne.srlinux.dev,resources=srlinuxes,verbs=get;list;watch;create;update;patch;delete
@@ -85,6 +116,8 @@ type SrlinuxReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -110,6 +143,11 @@ func (r *SrlinuxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return res, err
 	}
 
+	// the claim for the node state has to exist before the pod mounting it is created
+	if err := r.handleSrlinuxStorage(ctx, log, &update, srlinux); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Check if the srlinux pod already exists, if not create a new one
 	pod := &corev1.Pod{}
 
@@ -117,6 +155,10 @@ func (r *SrlinuxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return res, err
 	}
 
+	if err := r.handleSrlinuxService(ctx, log, &update, srlinux); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Update the srlinux status after pod creation/handling
 	if update {
 		if res, isReturn, err := r.updateSrlinuxStatus(ctx, log, req, srlinux); isReturn {
@@ -129,16 +171,36 @@ func (r *SrlinuxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
-	// SR Linux becomes ready when its Pod readiness probe succeeds.
+	// SR Linux management server becomes ready when its Pod readiness probe succeeds.
 	// The readiness probe checks if mgmt server is ready to accept config
-	if !srlinux.Status.Ready {
+	if !isPodReady(pod) {
 		log.Info("SR Linux management server is not yet ready, requeing...")
 
 		// wait 2 sec before requeuing as constant polling is not needed
 		return ctrl.Result{}, nil
 	}
 
-	r.handleSrlinuxStartupConfig(ctx, log, &update, srlinux)
+	// record SR Linux version, discovering it from the node if needed.
+	// When the pod is re-created to mount a license for the discovered version,
+	// startup config is handled once the new pod becomes ready.
+	// requeueAfter is set when the node needs to be looked at again, e.g. to retry the version discovery
+	// or to renew its certificate.
+	podRecreated, requeueAfter := r.handleSrlinuxVersion(ctx, log, &update, srlinux, pod)
+	if !podRecreated {
+		requeueAfter = earliestRequeue(requeueAfter, r.handleSrlinuxStartupConfig(ctx, log, &update, srlinux, pod))
+
+		recordStartupConfigPod(&update, srlinux, pod)
+
+		requeueAfter = earliestRequeue(requeueAfter, r.handleSrlinuxDrift(ctx, log, &update, srlinux, pod))
+
+		requeueAfter = earliestRequeue(requeueAfter, r.handleSrlinuxCertificate(ctx, log, &update, srlinux, pod))
+
+		requeueAfter = earliestRequeue(requeueAfter, r.handleSrlinuxReadiness(ctx, log, &update, srlinux, pod))
+
+		requeueAfter = earliestRequeue(requeueAfter, r.handleSrlinuxEndpoints(ctx, log, &update, srlinux, time.Now()))
+
+		requeueAfter = earliestRequeue(requeueAfter, r.handleSrlinuxConfigExport(ctx, log, &update, srlinux, pod))
+	}
 
 	// updating Srlinux status
 	if update {
@@ -147,7 +209,16 @@ func (r *SrlinuxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// earliestRequeue returns the shortest of the requeue durations, 0 durations mean no requeue.
+func earliestRequeue(a, b time.Duration) time.Duration {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+
+	return a
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -155,6 +226,7 @@ func (r *SrlinuxReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&srlinuxv1.Srlinux{}).
 		Owns(&corev1.Pod{}).
+		Owns(&corev1.Service{}).
 		Complete(r)
 }
 
@@ -174,6 +246,8 @@ func (r *SrlinuxReconciler) handleSrlinuxCR(
 			log.Info("Srlinux resource not found. Ignoring since object must be deleted",
 				"NamespacedName", req.NamespacedName)
 
+			r.Sessions.closeStale(log, req.NamespacedName, "")
+
 			return ctrl.Result{}, true, nil
 		}
 		// Error reading the object - requeue the request.
@@ -194,9 +268,46 @@ func (r *SrlinuxReconciler) handleSrlinuxPod(
 	srlinux *srlinuxv1.Srlinux,
 	pod *corev1.Pod,
 ) (ctrl.Result, bool, error) {
-	err := r.Get(ctx, types.NamespacedName{Name: srlinux.Name, Namespace: srlinux.Namespace}, pod)
+	nn := types.NamespacedName{Name: srlinux.Name, Namespace: srlinux.Namespace}
+
+	err := r.Get(ctx, nn, pod)
 	// if pod was not found, create a new one
 	if err != nil && errors.IsNotFound(err) {
+		// sessions to the gone pod are not usable anymore
+		r.Sessions.closeStale(log, nn, "")
+
+		// interfaces that don't fit into the variant can't be provisioned,
+		// the pod is created once the spec is fixed
+		if err := srlinux.Spec.ValidateInterfaces(); err != nil {
+			log.Error(err, "invalid interfaces in Srlinux spec")
+
+			srlinux.Status.Status = "error"
+			meta.SetStatusCondition(&srlinux.Status.Conditions, metav1.Condition{
+				Type:    srlinuxv1.ConditionInterfacesValid,
+				Status:  metav1.ConditionFalse,
+				Reason:  srlinuxv1.ReasonInvalidInterfaces,
+				Message: err.Error(),
+			})
+
+			res, _, err := r.updateSrlinuxStatus(ctx, log, ctrl.Request{}, srlinux)
+
+			return res, true, err
+		}
+
+		// the fixed interfaces are reported before the pod is created
+		if meta.IsStatusConditionFalse(srlinux.Status.Conditions, srlinuxv1.ConditionInterfacesValid) {
+			meta.SetStatusCondition(&srlinux.Status.Conditions, metav1.Condition{
+				Type:    srlinuxv1.ConditionInterfacesValid,
+				Status:  metav1.ConditionTrue,
+				Reason:  srlinuxv1.ReasonValidInterfaces,
+				Message: "interfaces fit into the variant",
+			})
+
+			if res, _, err := r.updateSrlinuxStatus(ctx, log, ctrl.Request{}, srlinux); err != nil {
+				return res, true, err
+			}
+		}
+
 		err = createConfigMaps(ctx, r, srlinux, log)
 		if err != nil {
 			return ctrl.Result{}, true, err
@@ -207,8 +318,53 @@ func (r *SrlinuxReconciler) handleSrlinuxPod(
 			return ctrl.Result{}, true, err
 		}
 
+		// the startup config sources are fetched and merged before the pod mounting the result is created
+		if err := r.createStartupConfigSecret(ctx, srlinux, log); err != nil {
+			log.Error(err, "failed to render startup config from the sources")
+
+			retry := recordStartupConfigSourcesFailure(log, srlinux, err, time.Now())
+
+			if res, _, err := r.updateSrlinuxStatus(ctx, log, ctrl.Request{}, srlinux); err != nil {
+				return res, true, err
+			}
+
+			if retry > 0 {
+				return ctrl.Result{RequeueAfter: retry}, true, nil
+			}
+
+			return ctrl.Result{}, true, err
+		}
+
+		// the fetched sources are reported before the pod is created
+		if meta.IsStatusConditionFalse(srlinux.Status.Conditions, srlinuxv1.ConditionStartupConfigSourcesFetched) {
+			meta.SetStatusCondition(&srlinux.Status.Conditions, metav1.Condition{
+				Type:    srlinuxv1.ConditionStartupConfigSourcesFetched,
+				Status:  metav1.ConditionTrue,
+				Reason:  srlinuxv1.ReasonFetched,
+				Message: "startup config sources are fetched and merged",
+			})
+
+			if res, _, err := r.updateSrlinuxStatus(ctx, log, ctrl.Request{}, srlinux); err != nil {
+				return res, true, err
+			}
+		}
+
+		// the startup config failed before the pod was created is processed anew on the new pod
+		if srlinux.Status.StartupConfig.Phase != "" && srlinux.Status.StartupConfig.PodUID == "" {
+			srlinux.Status.StartupConfig = srlinuxv1.StartupConfigStatus{}
+
+			if res, _, err := r.updateSrlinuxStatus(ctx, log, ctrl.Request{}, srlinux); err != nil {
+				return res, true, err
+			}
+		}
+
 		// Define a new srlinux pod
-		pod := r.podForSrlinux(ctx, srlinux)
+		pod, err := r.podForSrlinux(ctx, srlinux)
+		if err != nil {
+			log.Error(err, "failed to define new Pod")
+
+			return ctrl.Result{}, true, err
+		}
 
 		log.Info("creating a new pod")
 
@@ -229,10 +385,54 @@ func (r *SrlinuxReconciler) handleSrlinuxPod(
 		return ctrl.Result{}, true, err
 	}
 
+	// sessions opened to the previous pod of the node are closed once it is re-created
+	r.Sessions.closeStale(log, nn, pod.UID)
+
+	// evicted pod is deleted to have it re-created on the next reconciliation
+	if isPodEvicted(pod) {
+		log.Info("pod was evicted, deleting it to re-create", "message", pod.Status.Message)
+
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "failed to delete evicted Pod")
+
+			return ctrl.Result{}, true, err
+		}
+
+		r.Sessions.closeStale(log, nn, "")
+
+		return ctrl.Result{Requeue: true}, true, nil
+	}
+
+	handleSrlinuxPodRecreation(log, update, srlinux, pod)
+
 	// setting status of srlinux CR
 	if srlinux.Status.Image != pod.Spec.Containers[0].Image {
 		*update = true
 		srlinux.Status.Image = pod.Spec.Containers[0].Image
+
+		ref, err := srlinuxv1.ParseImageReference(srlinux.Status.Image)
+		if err != nil {
+			log.Error(err, "failed to parse image reference", "image", srlinux.Status.Image)
+		}
+
+		srlinux.Status.ImageReference = ref
+	}
+
+	if ifStatus := srlinux.Spec.GetInterfacesStatus(); !cmp.Equal(srlinux.Status.Interfaces, ifStatus) {
+		*update = true
+		srlinux.Status.Interfaces = ifStatus
+	}
+
+	if srlinux.Status.Management.IP != pod.Status.PodIP {
+		*update = true
+		srlinux.Status.Management.IP = pod.Status.PodIP
+		// endpoints are discovered anew for the new management IP
+		srlinux.Status.Management.Endpoints = nil
+		srlinux.Status.Management.EndpointsProbeTime = nil
+		// the certificate is installed on the new pod and re-issued to cover its IP
+		if srlinux.Status.Certificate != nil {
+			srlinux.Status.Certificate.InstalledSerial = ""
+		}
 	}
 
 	if srlinux.Status.Status != string(pod.Status.Phase) {
@@ -240,13 +440,23 @@ func (r *SrlinuxReconciler) handleSrlinuxPod(
 		srlinux.Status.Status = string(pod.Status.Phase)
 	}
 
-	if len(pod.Status.ContainerStatuses) > 0 {
-		if srlinux.Status.Ready != pod.Status.ContainerStatuses[0].Ready {
+	handleSrlinuxPodStatus(log, update, srlinux, pod)
+
+	// when readiness stages are configured, the pod readiness only gates the node readiness
+	// and the stages are evaluated once the management server is ready
+	podReady := isPodReady(pod)
+	if !srlinux.Spec.Readiness.HasStages() || !podReady {
+		if srlinux.Status.Ready != podReady {
 			*update = true
-			srlinux.Status.Ready = pod.Status.ContainerStatuses[0].Ready
+			srlinux.Status.Ready = podReady
 		}
 	}
 
+	if !podReady && srlinux.Status.Readiness != nil {
+		*update = true
+		srlinux.Status.Readiness = nil
+	}
+
 	return ctrl.Result{}, false, err
 }
 
@@ -11,6 +11,8 @@ import (
 	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -72,6 +74,122 @@ func TestSrlinuxReconcile(t *testing.T) {
 			},
 			testFn: testReconcileForBasicSrlCR,
 		},
+		{
+			descr: "SR Linux CR with management service",
+			clientObjs: []runtime.Object{
+				&srlinuxv1.Srlinux{
+					ObjectMeta: ctrl.ObjectMeta{
+						Name:      defaultCRName,
+						Namespace: defaultNamespace,
+					},
+					Spec: srlinuxv1.SrlinuxSpec{
+						Config: &srlinuxv1.NodeConfig{
+							Image: defaultSrlinuxImage,
+						},
+						Service: &srlinuxv1.ServiceSpec{
+							Type: corev1.ServiceTypeLoadBalancer,
+						},
+					},
+				},
+			},
+			testFn: testReconcileForSrlCRWithService,
+		},
+		{
+			descr: "SR Linux CR with persistent storage",
+			clientObjs: []runtime.Object{
+				&srlinuxv1.Srlinux{
+					ObjectMeta: ctrl.ObjectMeta{
+						Name:      defaultCRName,
+						Namespace: defaultNamespace,
+					},
+					Spec: srlinuxv1.SrlinuxSpec{
+						Config: &srlinuxv1.NodeConfig{
+							Image: defaultSrlinuxImage,
+						},
+						Storage: &srlinuxv1.StorageSpec{},
+					},
+				},
+			},
+			testFn: testReconcileForSrlCRWithStorage,
+		},
+		{
+			descr: "SR Linux pod re-created after startup config was loaded",
+			clientObjs: []runtime.Object{
+				&srlinuxv1.Srlinux{
+					ObjectMeta: ctrl.ObjectMeta{
+						Name:      defaultCRName,
+						Namespace: defaultNamespace,
+					},
+					Spec: srlinuxv1.SrlinuxSpec{
+						Config: &srlinuxv1.NodeConfig{
+							Image: defaultSrlinuxImage,
+						},
+					},
+					Status: srlinuxv1.SrlinuxStatus{
+						StartupConfig: srlinuxv1.StartupConfigStatus{Phase: "loaded", PodUID: "old-pod-uid"},
+					},
+				},
+				&corev1.Pod{
+					ObjectMeta: ctrl.ObjectMeta{
+						Name:      defaultCRName,
+						Namespace: defaultNamespace,
+						UID:       "new-pod-uid",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: defaultCRName, Image: defaultSrlinuxImage}},
+					},
+				},
+			},
+			testFn: testReconcileForRecreatedPod,
+		},
+		{
+			descr: "SR Linux pod evicted",
+			clientObjs: []runtime.Object{
+				&srlinuxv1.Srlinux{
+					ObjectMeta: ctrl.ObjectMeta{
+						Name:      defaultCRName,
+						Namespace: defaultNamespace,
+					},
+					Spec: srlinuxv1.SrlinuxSpec{
+						Config: &srlinuxv1.NodeConfig{
+							Image: defaultSrlinuxImage,
+						},
+					},
+				},
+				&corev1.Pod{
+					ObjectMeta: ctrl.ObjectMeta{
+						Name:      defaultCRName,
+						Namespace: defaultNamespace,
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: defaultCRName, Image: defaultSrlinuxImage}},
+					},
+					Status: corev1.PodStatus{
+						Phase:  corev1.PodFailed,
+						Reason: podEvictedReason,
+					},
+				},
+			},
+			testFn: testReconcileForEvictedPod,
+		},
+		{
+			descr: "SR Linux CR with invalid interfaces",
+			clientObjs: []runtime.Object{
+				&srlinuxv1.Srlinux{
+					ObjectMeta: ctrl.ObjectMeta{
+						Name:      defaultCRName,
+						Namespace: defaultNamespace,
+					},
+					Spec: srlinuxv1.SrlinuxSpec{
+						Config: &srlinuxv1.NodeConfig{
+							Image: defaultSrlinuxImage,
+						},
+						Interfaces: []srlinuxv1.InterfaceSpec{{Name: "eth1"}},
+					},
+				},
+			},
+			testFn: testReconcileForInvalidInterfaces,
+		},
 		{
 			descr:      "SR Linux CR doesn't exists (e.g. deleted)",
 			clientObjs: []runtime.Object{},
@@ -116,6 +234,166 @@ func testReconcileForBasicSrlCR(_ *testing.T, c client.Client, reconciler Srlinu
 	g.Expect(srlinux.Status.Image).To(Equal(defaultSrlinuxImage))
 }
 
+func testReconcileForSrlCRWithService(_ *testing.T, c client.Client, reconciler SrlinuxReconciler, g *GomegaWithT) {
+	g.Eventually(func() bool {
+		res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: namespacedName,
+		})
+
+		return res.IsZero() && err == nil
+	}, 10*time.Second, time.Second).Should(BeTrue())
+
+	// check if the management Service for Srlinux CR has been created
+	svc := &corev1.Service{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: defaultCRName + "-mgmt", Namespace: defaultNamespace}, svc)).
+		To(Succeed())
+	g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+	g.Expect(svc.Spec.Ports).To(HaveLen(4))
+
+	// check if CR status is updated
+	srlinux := &srlinuxv1.Srlinux{}
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+	g.Expect(srlinux.Status.Management.Service).To(Equal(svc.Name))
+
+	// change service type and ports and check if the Service is kept in sync
+	srlinux.Spec.Service = &srlinuxv1.ServiceSpec{
+		Type:  corev1.ServiceTypeClusterIP,
+		Ports: []srlinuxv1.ManagementEndpoint{{Name: srlinuxv1.EndpointSSH, Port: 22}},
+	}
+	g.Expect(c.Update(ctx, srlinux)).To(Succeed())
+
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(svc), svc)).To(Succeed())
+	g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+	g.Expect(svc.Spec.Ports).To(HaveLen(1))
+
+	// annotate the Service from the spec and by others
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+	srlinux.Spec.Service.Annotations = map[string]string{"a": "1", "b": "2"}
+	g.Expect(c.Update(ctx, srlinux)).To(Succeed())
+
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(svc), svc)).To(Succeed())
+	g.Expect(svc.Annotations).To(HaveKeyWithValue("b", "2"))
+	svc.Annotations["lb"] = "assigned"
+	g.Expect(c.Update(ctx, svc)).To(Succeed())
+
+	// remove an annotation from the spec and check if it is removed from the Service
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+	srlinux.Spec.Service.Annotations = map[string]string{"a": "1"}
+	g.Expect(c.Update(ctx, srlinux)).To(Succeed())
+
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(svc), svc)).To(Succeed())
+	g.Expect(svc.Annotations).To(HaveKeyWithValue("a", "1"))
+	g.Expect(svc.Annotations).ToNot(HaveKey("b"))
+	g.Expect(svc.Annotations).To(HaveKeyWithValue("lb", "assigned"))
+
+	// remove service from the spec and check if the Service is deleted
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+	srlinux.Spec.Service = nil
+	g.Expect(c.Update(ctx, srlinux)).To(Succeed())
+
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(svc), svc)).ToNot(Succeed())
+}
+
+func testReconcileForSrlCRWithStorage(_ *testing.T, c client.Client, reconciler SrlinuxReconciler, g *GomegaWithT) {
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	srlinux := &srlinuxv1.Srlinux{}
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+
+	// check if the claim is created and owned by the CR with the default retention policy
+	pvc := &corev1.PersistentVolumeClaim{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Name: defaultCRName + "-storage", Namespace: defaultNamespace}, pvc)).
+		To(Succeed())
+	g.Expect(metav1.IsControlledBy(pvc, srlinux)).To(BeTrue())
+
+	// check if the claim is mounted to the pod
+	pod := &corev1.Pod{}
+	g.Expect(c.Get(ctx, namespacedName, pod)).To(Succeed())
+	g.Expect(pod.Spec.Volumes).To(ContainElement(createStorageVolume(srlinux)))
+
+	// retain the claim and check if it is released from the CR ownership
+	srlinux.Spec.Storage.RetentionPolicy = srlinuxv1.StorageRetentionRetain
+	g.Expect(c.Update(ctx, srlinux)).To(Succeed())
+
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+	g.Expect(metav1.IsControlledBy(pvc, srlinux)).To(BeFalse())
+
+	// check if CR status is updated
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+	g.Expect(srlinux.Status.StorageClaim).To(Equal(pvc.Name))
+}
+
+func testReconcileForRecreatedPod(_ *testing.T, c client.Client, reconciler SrlinuxReconciler, g *GomegaWithT) {
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// check if provisioning is reset for the re-created pod
+	srlinux := &srlinuxv1.Srlinux{}
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+	g.Expect(srlinux.Status.StartupConfig.Phase).To(BeEmpty())
+	g.Expect(srlinux.Status.StartupConfig.PodUID).To(BeEmpty())
+}
+
+func testReconcileForEvictedPod(_ *testing.T, c client.Client, reconciler SrlinuxReconciler, g *GomegaWithT) {
+	res, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(res.Requeue).To(BeTrue())
+
+	// check if the evicted pod is deleted
+	pod := &corev1.Pod{}
+	g.Expect(c.Get(ctx, namespacedName, pod)).ToNot(Succeed())
+
+	// check if the pod is re-created on the next reconciliation
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.Get(ctx, namespacedName, pod)).To(Succeed())
+}
+
+func testReconcileForInvalidInterfaces(_ *testing.T, c client.Client, reconciler SrlinuxReconciler, g *GomegaWithT) {
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// check if the pod is not created and the reason is reported
+	pod := &corev1.Pod{}
+	g.Expect(c.Get(ctx, namespacedName, pod)).ToNot(Succeed())
+
+	srlinux := &srlinuxv1.Srlinux{}
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+	g.Expect(srlinux.Status.Status).To(Equal("error"))
+
+	cond := meta.FindStatusCondition(srlinux.Status.Conditions, srlinuxv1.ConditionInterfacesValid)
+	g.Expect(cond).ToNot(BeNil())
+	g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(cond.Message).To(ContainSubstring(`"eth1"`))
+
+	// fix the interfaces and check if the pod is created
+	srlinux.Spec.Interfaces = []srlinuxv1.InterfaceSpec{{Name: "ethernet-1/1"}}
+	g.Expect(c.Update(ctx, srlinux)).To(Succeed())
+
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: namespacedName})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.Get(ctx, namespacedName, pod)).To(Succeed())
+
+	g.Expect(c.Get(ctx, namespacedName, srlinux)).To(Succeed())
+	g.Expect(meta.IsStatusConditionTrue(srlinux.Status.Conditions, srlinuxv1.ConditionInterfacesValid)).To(BeTrue())
+}
+
 func testReconcileForDeletedCR(_ *testing.T, c client.Client, reconciler SrlinuxReconciler, g *GomegaWithT) {
 	g.Eventually(func() bool {
 		res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
@@ -0,0 +1,314 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/scrapli/scrapligo/driver/opoptions"
+	"github.com/scrapli/scrapligo/response"
+	"github.com/scrapli/scrapligo/util"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// commandNodeWaitInterval is the interval the nodes the commands are pending on are looked at.
+	commandNodeWaitInterval = 10 * time.Second
+	// commandOutputMaxLen limits the length of the command output kept in the status.
+	commandOutputMaxLen = 4096
+)
+
+// SrlinuxCommandReconciler reconciles a SrlinuxCommand object.
+type SrlinuxCommandReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Sessions is the pool of the management sessions to the nodes shared with the Srlinux controller.
+	// Without the pool a session is opened for every use.
+	Sessions *SessionPool
+
+	// openSession opens a management session to the node, when nil a session is taken from the Sessions pool.
+	openSession func(ctx context.Context, log logr.Logger, srl *srlinuxv1.Srlinux) (commandSession, func(err error))
+}
+
+// commandSession is a management session the commands are sent over.
+type commandSession interface {
+	SendCommand(command string, opts ...util.Option) (*response.Response, error)
+}
+
+//+kubebuilder:rbac:groups=kne.srlinux.dev,resources=srlinuxcommands,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kne.srlinux.dev,resources=srlinuxcommands/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kne.srlinux.dev,resources=srlinuxes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+
+// Reconcile runs the commands on the selected nodes once they are ready.
+// The commands are run once per node; a SrlinuxCommand that succeeded or failed is not reconciled anymore.
+func (r *SrlinuxCommandReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	cmd := &srlinuxv1.SrlinuxCommand{}
+	if err := r.Get(ctx, req.NamespacedName, cmd); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	if cmd.Status.Phase == srlinuxv1.CommandPhaseSucceeded || cmd.Status.Phase == srlinuxv1.CommandPhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	nodes, err := r.selectNodes(ctx, cmd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	base := cmd.DeepCopy()
+
+	statuses := make([]srlinuxv1.NodeCommandStatus, 0, len(nodes))
+
+	for _, name := range nodes {
+		st := srlinuxv1.NodeCommandStatus{Node: name, Phase: srlinuxv1.CommandPhasePending}
+		if i := slices.IndexFunc(cmd.Status.Nodes, func(n srlinuxv1.NodeCommandStatus) bool { return n.Node == name }); i >= 0 {
+			st = cmd.Status.Nodes[i]
+		}
+
+		statuses = append(statuses, st)
+	}
+
+	cmd.Status.Nodes = statuses
+
+	for i, name := range nodes {
+		if cmd.Status.Nodes[i].Phase != srlinuxv1.CommandPhasePending {
+			continue
+		}
+
+		st := r.runOnNode(ctx, log, cmd, name)
+		cmd.Status.Nodes[i] = st
+
+		if st.Phase == srlinuxv1.CommandPhasePending {
+			continue
+		}
+
+		if cmd.Status.StartTime == nil {
+			cmd.Status.StartTime = &metav1.Time{Time: time.Now()}
+		}
+
+		// the result is recorded right after the commands ran on the node,
+		// so that they are not run on it again when the status fails to be recorded for the following nodes
+		setCommandPhase(cmd)
+
+		if err := r.Status().Patch(ctx, cmd, client.MergeFrom(base)); err != nil {
+			log.Error(err, "failed to patch SrlinuxCommand status", "node", name)
+
+			return ctrl.Result{}, err
+		}
+
+		base = cmd.DeepCopy()
+	}
+
+	setCommandPhase(cmd)
+
+	if !cmp.Equal(base.Status, cmd.Status) {
+		if err := r.Status().Patch(ctx, cmd, client.MergeFrom(base)); err != nil {
+			log.Error(err, "failed to patch SrlinuxCommand status")
+
+			return ctrl.Result{}, err
+		}
+	}
+
+	if cmd.Status.Phase == srlinuxv1.CommandPhasePending {
+		return ctrl.Result{RequeueAfter: commandNodeWaitInterval}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// selectNodes returns the names of the nodes the commands are run on: the nodes named in the spec,
+// in order, followed by the nodes selected by the labels, sorted by name.
+func (r *SrlinuxCommandReconciler) selectNodes(ctx context.Context, cmd *srlinuxv1.SrlinuxCommand) ([]string, error) {
+	nodes := slices.Clone(cmd.Spec.Nodes)
+
+	if cmd.Spec.Selector != nil {
+		sel, err := metav1.LabelSelectorAsSelector(cmd.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+
+		l := &srlinuxv1.SrlinuxList{}
+		if err := r.List(ctx, l, client.InNamespace(cmd.Namespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+			return nil, err
+		}
+
+		selected := make([]string, 0, len(l.Items))
+		for i := range l.Items {
+			selected = append(selected, l.Items[i].Name)
+		}
+
+		slices.Sort(selected)
+
+		nodes = append(nodes, selected...)
+	}
+
+	// a node both named and selected is run on once, in the named order
+	var unique []string
+
+	for _, n := range nodes {
+		if !slices.Contains(unique, n) {
+			unique = append(unique, n)
+		}
+	}
+
+	return unique, nil
+}
+
+// runOnNode runs the commands on the node. The commands stay pending on a node that is not found or not ready,
+// or when a management session to the node couldn't be established.
+func (r *SrlinuxCommandReconciler) runOnNode(
+	ctx context.Context,
+	log logr.Logger,
+	cmd *srlinuxv1.SrlinuxCommand,
+	name string,
+) srlinuxv1.NodeCommandStatus {
+	st := srlinuxv1.NodeCommandStatus{Node: name, Phase: srlinuxv1.CommandPhasePending}
+
+	srl := &srlinuxv1.Srlinux{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: cmd.Namespace}, srl); err != nil {
+		st.Message = err.Error()
+
+		return st
+	}
+
+	if !srl.Status.Ready || srl.Status.Management.IP == "" {
+		st.Message = "waiting for the node to be ready"
+
+		return st
+	}
+
+	log = log.WithValues("node", name)
+
+	session, release := r.nodeSession(ctx, log, srl)
+	if session == nil {
+		st.Message = "management session is not established"
+
+		return st
+	}
+
+	var sessionErr error
+
+	defer func() { release(sessionErr) }()
+
+	st.Phase = srlinuxv1.CommandPhaseSucceeded
+	st.Message = ""
+
+	for _, c := range cmd.Spec.Commands {
+		res := srlinuxv1.CommandResult{Command: c}
+
+		resp, err := session.SendCommand(c, opoptions.WithTimeoutOps(cmd.Spec.GetTimeout()))
+
+		switch {
+		case err != nil:
+			sessionErr = err
+			res.Error = err.Error()
+		case resp.Failed != nil:
+			res.Output = truncate(resp.Result, commandOutputMaxLen)
+			res.Error = resp.Failed.Error()
+		default:
+			res.Output = truncate(resp.Result, commandOutputMaxLen)
+		}
+
+		st.Results = append(st.Results, res)
+
+		if res.Error != "" {
+			log.Info("command failed", "command", c, "error", res.Error)
+
+			st.Phase = srlinuxv1.CommandPhaseFailed
+
+			if !cmd.Spec.ContinueOnError {
+				break
+			}
+		}
+	}
+
+	return st
+}
+
+// nodeSession returns a management session to the node and a func releasing it,
+// called with the error the session failed with, if any. The session is nil if it couldn't be established.
+func (r *SrlinuxCommandReconciler) nodeSession(
+	ctx context.Context,
+	log logr.Logger,
+	srl *srlinuxv1.Srlinux,
+) (commandSession, func(err error)) {
+	if r.openSession != nil {
+		return r.openSession(ctx, log, srl)
+	}
+
+	// the sessions are pooled per pod
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, types.NamespacedName{Name: srl.Name, Namespace: srl.Namespace}, pod); err != nil {
+		log.Error(err, "failed to get the pod of the node")
+
+		return nil, nil
+	}
+
+	driver := r.Sessions.acquire(ctx, log, nodeOf(pod), pod.UID, pod.Status.PodIP)
+	if driver == nil {
+		return nil, nil
+	}
+
+	return driver, func(err error) { r.Sessions.release(log, driver, err) }
+}
+
+// setCommandPhase sets the phase of the commands from their phases on the nodes
+// and the completion time once they are no longer pending.
+func setCommandPhase(cmd *srlinuxv1.SrlinuxCommand) {
+	cmd.Status.Phase = commandPhase(cmd.Status.Nodes)
+
+	if cmd.Status.Phase != srlinuxv1.CommandPhasePending && cmd.Status.CompletionTime == nil {
+		cmd.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	}
+}
+
+// commandPhase returns the phase of the commands from their phases on the nodes.
+// The commands are pending while they are pending on a node, and failed when they failed on any node.
+// Commands with no nodes selected are pending, as the nodes may be created later.
+func commandPhase(nodes []srlinuxv1.NodeCommandStatus) string {
+	if len(nodes) == 0 {
+		return srlinuxv1.CommandPhasePending
+	}
+
+	phase := srlinuxv1.CommandPhaseSucceeded
+
+	for _, n := range nodes {
+		switch n.Phase {
+		case srlinuxv1.CommandPhasePending:
+			return srlinuxv1.CommandPhasePending
+		case srlinuxv1.CommandPhaseFailed:
+			phase = srlinuxv1.CommandPhaseFailed
+		}
+	}
+
+	return phase
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SrlinuxCommandReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&srlinuxv1.SrlinuxCommand{}).
+		Complete(r)
+}
@@ -0,0 +1,256 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/scrapli/scrapligo/response"
+	"github.com/scrapli/scrapligo/util"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSrlinuxCommandReconcile(t *testing.T) {
+	srlinuxes := []runtime.Object{
+		&srlinuxv1.Srlinux{
+			ObjectMeta: metav1.ObjectMeta{Name: "srl2", Namespace: defaultNamespace, Labels: map[string]string{"role": "leaf"}},
+		},
+		&srlinuxv1.Srlinux{
+			ObjectMeta: metav1.ObjectMeta{Name: "srl1", Namespace: defaultNamespace, Labels: map[string]string{"role": "leaf"}},
+		},
+		&srlinuxv1.Srlinux{
+			ObjectMeta: metav1.ObjectMeta{Name: "spine1", Namespace: defaultNamespace, Labels: map[string]string{"role": "spine"}},
+		},
+	}
+
+	cmd := &srlinuxv1.SrlinuxCommand{
+		ObjectMeta: metav1.ObjectMeta{Name: "clear-counters", Namespace: defaultNamespace},
+		Spec: srlinuxv1.SrlinuxCommandSpec{
+			Nodes:    []string{"srl2", "missing"},
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "leaf"}},
+			Commands: []string{"/tools interface ethernet-1/1 statistics clear"},
+		},
+	}
+
+	r := &SrlinuxCommandReconciler{
+		Client: fake.NewClientBuilder().
+			WithRuntimeObjects(append(srlinuxes, cmd)...).
+			WithStatusSubresource(&srlinuxv1.SrlinuxCommand{}).
+			Build(),
+		Scheme: scheme.Scheme,
+	}
+
+	nn := types.NamespacedName{Name: cmd.Name, Namespace: defaultNamespace}
+
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: nn})
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	if res.RequeueAfter != commandNodeWaitInterval {
+		t.Fatalf("expected requeue while the nodes are not ready, got %+v", res)
+	}
+
+	got := &srlinuxv1.SrlinuxCommand{}
+	if err := r.Get(ctx, nn, got); err != nil {
+		t.Fatalf("failed to get SrlinuxCommand: %v", err)
+	}
+
+	nodes := make([]string, 0, len(got.Status.Nodes))
+	for _, n := range got.Status.Nodes {
+		if n.Phase != srlinuxv1.CommandPhasePending || n.Message == "" {
+			t.Fatalf("expected node %s to be pending with a message, got %+v", n.Node, n)
+		}
+
+		nodes = append(nodes, n.Node)
+	}
+
+	want := []string{"srl2", "missing", "srl1"}
+	if got.Status.Phase != srlinuxv1.CommandPhasePending || !cmp.Equal(nodes, want) {
+		t.Fatalf("actual and expected inputs do not match\nactual: %s %+v\nexpected:%s %+v",
+			got.Status.Phase, nodes, srlinuxv1.CommandPhasePending, want)
+	}
+}
+
+// fakeCommandSession records the commands sent and replies to them with "ok".
+type fakeCommandSession struct {
+	sent *[]string
+}
+
+func (s *fakeCommandSession) SendCommand(command string, _ ...util.Option) (*response.Response, error) {
+	*s.sent = append(*s.sent, command)
+
+	return &response.Response{Input: command, Result: "ok"}, nil
+}
+
+func TestSrlinuxCommandReconcileRunsCommands(t *testing.T) {
+	readyNode := func(name, ip string) *srlinuxv1.Srlinux {
+		return &srlinuxv1.Srlinux{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: defaultNamespace},
+			Status: srlinuxv1.SrlinuxStatus{
+				Ready:      true,
+				Management: srlinuxv1.ManagementStatus{IP: ip},
+			},
+		}
+	}
+
+	cmd := &srlinuxv1.SrlinuxCommand{
+		ObjectMeta: metav1.ObjectMeta{Name: "show-version", Namespace: defaultNamespace},
+		Spec: srlinuxv1.SrlinuxCommandSpec{
+			Nodes:    []string{"srl1", "srl2"},
+			Commands: []string{"show version", "show interface"},
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithRuntimeObjects(readyNode("srl1", "10.0.0.1"), readyNode("srl2", "10.0.0.2"), cmd).
+		WithStatusSubresource(&srlinuxv1.SrlinuxCommand{}).
+		Build()
+
+	nn := types.NamespacedName{Name: cmd.Name, Namespace: defaultNamespace}
+
+	var sent []string
+
+	unreachable := map[string]bool{"srl2": true}
+
+	r := &SrlinuxCommandReconciler{
+		Client: c,
+		Scheme: scheme.Scheme,
+		openSession: func(_ context.Context, _ logr.Logger, srl *srlinuxv1.Srlinux) (commandSession, func(error)) {
+			if unreachable[srl.Name] {
+				// the result of the node the commands ran on is recorded before the next node is looked at
+				got := &srlinuxv1.SrlinuxCommand{}
+				if err := c.Get(ctx, nn, got); err != nil || len(got.Status.Nodes) == 0 ||
+					got.Status.Nodes[0].Phase != srlinuxv1.CommandPhaseSucceeded {
+					t.Fatalf("expected the result of srl1 to be recorded, got %+v, error: %v", got.Status, err)
+				}
+
+				return nil, nil
+			}
+
+			return &fakeCommandSession{sent: &sent}, func(error) {}
+		},
+	}
+
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: nn})
+	if err != nil || res.RequeueAfter != commandNodeWaitInterval {
+		t.Fatalf("expected requeue while the session to srl2 is not established, got %+v, error: %v", res, err)
+	}
+
+	got := &srlinuxv1.SrlinuxCommand{}
+	if err := c.Get(ctx, nn, got); err != nil {
+		t.Fatalf("failed to get SrlinuxCommand: %v", err)
+	}
+
+	wantResults := []srlinuxv1.CommandResult{
+		{Command: "show version", Output: "ok"},
+		{Command: "show interface", Output: "ok"},
+	}
+
+	if got.Status.Phase != srlinuxv1.CommandPhasePending || got.Status.StartTime == nil ||
+		got.Status.Nodes[1].Phase != srlinuxv1.CommandPhasePending || got.Status.Nodes[1].Message == "" ||
+		!cmp.Equal(got.Status.Nodes[0].Results, wantResults) {
+		t.Fatalf("actual and expected inputs do not match\nactual: %+v\nexpected:%+v", got.Status, wantResults)
+	}
+
+	// srl2 becomes reachable, the commands are not run on srl1 again
+	unreachable["srl2"] = false
+	sent = nil
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: nn}); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	if err := c.Get(ctx, nn, got); err != nil {
+		t.Fatalf("failed to get SrlinuxCommand: %v", err)
+	}
+
+	if got.Status.Phase != srlinuxv1.CommandPhaseSucceeded || got.Status.CompletionTime == nil ||
+		!cmp.Equal(sent, cmd.Spec.Commands) {
+		t.Fatalf("actual and expected inputs do not match\nactual: %s %+v\nexpected:%s %+v",
+			got.Status.Phase, sent, srlinuxv1.CommandPhaseSucceeded, cmd.Spec.Commands)
+	}
+}
+
+func TestSrlinuxCommandNodeSessionPooled(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "srl1", Namespace: defaultNamespace, UID: "uid1"},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+	}
+
+	p, f := newFakeSessionPool()
+
+	r := &SrlinuxCommandReconciler{
+		Client:   fake.NewClientBuilder().WithRuntimeObjects(pod).Build(),
+		Scheme:   scheme.Scheme,
+		Sessions: p,
+	}
+
+	srl := &srlinuxv1.Srlinux{ObjectMeta: metav1.ObjectMeta{Name: "srl1", Namespace: defaultNamespace}}
+
+	s, release := r.nodeSession(ctx, ctrl.Log, srl)
+	if s == nil {
+		t.Fatalf("expected a session to the node")
+	}
+
+	release(nil)
+
+	// the session released by the command is reused by the Srlinux controller
+	if d := p.acquire(ctx, ctrl.Log, nodeOf(pod), pod.UID, pod.Status.PodIP); d != s || f.opened != 1 {
+		t.Fatalf("expected the pooled session to be reused, opened %d sessions", f.opened)
+	}
+}
+
+func TestCommandPhase(t *testing.T) {
+	tests := []struct {
+		desc   string
+		phases []string
+		want   string
+	}{
+		{
+			desc: "no nodes",
+			want: srlinuxv1.CommandPhasePending,
+		},
+		{
+			desc:   "pending on a node",
+			phases: []string{srlinuxv1.CommandPhaseFailed, srlinuxv1.CommandPhasePending},
+			want:   srlinuxv1.CommandPhasePending,
+		},
+		{
+			desc:   "failed on a node",
+			phases: []string{srlinuxv1.CommandPhaseSucceeded, srlinuxv1.CommandPhaseFailed},
+			want:   srlinuxv1.CommandPhaseFailed,
+		},
+		{
+			desc:   "succeeded on every node",
+			phases: []string{srlinuxv1.CommandPhaseSucceeded, srlinuxv1.CommandPhaseSucceeded},
+			want:   srlinuxv1.CommandPhaseSucceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			nodes := make([]srlinuxv1.NodeCommandStatus, 0, len(tt.phases))
+			for _, p := range tt.phases {
+				nodes = append(nodes, srlinuxv1.NodeCommandStatus{Phase: p})
+			}
+
+			if got := commandPhase(nodes); got != tt.want {
+				t.Fatalf("%s: expected phase %s, got %s", tt.desc, tt.want, got)
+			}
+		},
+		)
+	}
+}
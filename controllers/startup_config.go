@@ -3,12 +3,12 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/scrapli/scrapligo/driver/network"
+	"github.com/scrapli/scrapligo/driver/opoptions"
 	"github.com/scrapli/scrapligo/driver/options"
 	"github.com/scrapli/scrapligo/platform"
 	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
@@ -24,6 +24,12 @@ const (
 	// the default for config file name resides within kne.
 	defaultConfigPath = "/tmp/startup-config"
 
+	// initCheckpoint is the name of the checkpoint holding the config the node was provisioned with.
+	initCheckpoint = "initial"
+	// preLoadCheckpoint is the name of the checkpoint holding the config the node had before
+	// the startup config was loaded.
+	preLoadCheckpoint = "pre-startup-config"
+
 	podIPReadyTimeout  = 60 * time.Second
 	podIPReadyInterval = 2 * time.Second
 )
@@ -48,7 +54,7 @@ func createStartupConfigVolumesAndMounts(s *srlinuxv1.Srlinux, pod *corev1.Pod,
 		cfgPath,
 	)
 
-	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+	vol := corev1.Volume{
 		Name: "startup-config-volume",
 		VolumeSource: corev1.VolumeSource{
 			// kne creates the configmap with the name <node-name>-config,
@@ -59,7 +65,19 @@ func createStartupConfigVolumesAndMounts(s *srlinuxv1.Srlinux, pod *corev1.Pod,
 				},
 			},
 		},
-	})
+	}
+
+	// the config merged from the startup config sources (including the kne config)
+	// or the kne config converted to a loadable format is stored in the secret
+	if usesStartupConfigSecret(s) {
+		vol.VolumeSource = corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: startupConfigSecretName(s),
+			},
+		}
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, vol)
 
 	pod.Spec.Containers[0].VolumeMounts = append(
 		pod.Spec.Containers[0].VolumeMounts,
@@ -72,18 +90,43 @@ func createStartupConfigVolumesAndMounts(s *srlinuxv1.Srlinux, pod *corev1.Pod,
 }
 
 // handleSrlinuxStartupConfig handles the startup config provisioning.
+// The returned duration is the time after which the startup config needs to be handled again,
+// e.g. when the templated config rendered with the pod IP is not yet synced to the pod
+// or when loading the config failed for a transient reason; 0 means never.
 func (r *SrlinuxReconciler) handleSrlinuxStartupConfig( //nolint:funlen
 	ctx context.Context,
 	log logr.Logger,
 	update *bool,
 	srlinux *srlinuxv1.Srlinux,
-) {
-	if srlinux.Status.StartupConfig.Phase != "" {
+	pod *corev1.Pod,
+) time.Duration {
+	switch srlinux.Status.StartupConfig.Phase {
+	case "":
+	case "retrying":
+		if wait := startupConfigRetryWait(srlinux, time.Now()); wait > 0 {
+			return wait
+		}
+	default:
 		log.Info("startup config already processed, skipping")
 
-		return
+		return 0
+	}
+
+	wait, err := r.syncRenderedStartupConfig(ctx, log, srlinux, pod)
+	if err != nil {
+		log.Error(err, "failed to render startup configuration")
+
+		return recordStartupConfigAttempt(log, update, srlinux, "", err, time.Now())
+	}
+
+	if wait > 0 {
+		log.Info("waiting for the rendered startup config to be synced to the pod", "wait", wait)
+
+		return wait
 	}
 
+	f := featuresFor(srlinux.GetVersion())
+
 	// we need to wait for podIP to be ready as well as the network to be ready
 	// we do this before even checking if the startup config is provided
 	// because we need to create a checkpoing in any case
@@ -92,80 +135,155 @@ func (r *SrlinuxReconciler) handleSrlinuxStartupConfig( //nolint:funlen
 	// even though the SR Linux management server is ready, the network might not be ready yet
 	// which results in transport errors when trying to open the scrapligo network driver.
 	// Hence we need to wait for the network to be ready.
-	driver := r.waitNetworkReady(ctx, log, ip)
+	driver := r.waitNetworkReady(ctx, log, pod, ip)
 	if driver == nil {
-		return
+		err := &startupConfigLoadError{transient: true, err: ErrNetworkNotReady}
+
+		return recordStartupConfigAttempt(log, update, srlinux, "", err, time.Now())
 	}
-	defer func() {
-		if err := driver.Close(); err != nil {
-			log.Error(err, "failed to close driver")
+
+	// err is the error of the last operation over the session, the failed session is not reused
+	defer func() { r.releaseNetworkDriver(log, driver, err) }()
+
+	// node with persistent storage keeps the config it saved before the pod restart or re-creation,
+	// the initial checkpoint indicates the node was provisioned before
+	if srlinux.Spec.Storage != nil {
+		var ok bool
+		if ok, err = hasInitCheckpoint(driver, f); err == nil && ok {
+			log.Info("node runs with the persisted config, skipping startup config")
+
+			return recordStartupConfigAttempt(log, update, srlinux, "persisted", nil, time.Now())
 		}
-	}()
+	}
 
-	// if startup config data is not provided and Phase hasn't been set yet, set the Startup Config state to "not-provided"
+	// if startup config data is not provided, set the Startup Config state to "not-provided"
 	// and create a checkpoint
-	if !srlinux.Spec.GetConfig().ConfigDataPresent && srlinux.Status.StartupConfig.Phase == "" {
+	if !srlinux.Spec.HasStartupConfig() {
 		log.Info("no startup config data provided")
 
-		srlinux.Status.StartupConfig.Phase = "not-provided"
-		*update = true
+		recordStartupConfigAttempt(log, update, srlinux, "not-provided", nil, time.Now())
 
-		err := createInitCheckpoint(ctx, driver, log)
+		err = applyInterfaceConfig(ctx, driver, f, srlinux, log)
+		if err != nil {
+			log.Error(err, "failed to apply interface configuration")
+		}
+
+		err = createInitCheckpoint(ctx, driver, f, log)
 		if err != nil {
 			log.Error(err, "failed to create initial checkpoint")
 		}
 
-		return
+		return 0
 	}
 
-	log.Info("Loading provided startup configuration...", "filename",
-		srlinux.Spec.GetConfig().ConfigFile, "path", defaultConfigPath)
-
-	err := loadStartupConfig(ctx, driver, srlinux.Spec.GetConfig().ConfigFile, log)
+	fileNames, err := r.startupConfigFileNames(ctx, srlinux)
 	if err != nil {
-		srlinux.Status.StartupConfig.Phase = "failed"
-		*update = true
+		log.Error(err, "failed to get startup configuration file names")
 
+		return recordStartupConfigAttempt(log, update, srlinux, "", err, time.Now())
+	}
+
+	log.Info("Loading provided startup configuration...", "filenames", fileNames, "path", defaultConfigPath)
+
+	err = loadStartupConfig(ctx, driver, f, fileNames, log)
+	if err != nil {
 		log.Error(err, "failed to load provided startup configuration")
 
-		return
+		return recordStartupConfigAttempt(log, update, srlinux, "", err, time.Now())
 	}
 
 	log.Info("Loaded provided startup configuration...")
 
-	srlinux.Status.StartupConfig.Phase = "loaded"
-	*update = true
+	recordStartupConfigAttempt(log, update, srlinux, "loaded", nil, time.Now())
+
+	err = applyInterfaceConfig(ctx, driver, f, srlinux, log)
+	if err != nil {
+		log.Error(err, "failed to apply interface configuration")
+	}
 
-	err = createInitCheckpoint(ctx, driver, log)
+	err = createInitCheckpoint(ctx, driver, f, log)
 	if err != nil {
 		log.Error(err, "failed to create initial checkpoint after loading startup config")
 	}
+
+	return 0
 }
 
-// loadStartupConfig loads the provided startup config into the SR Linux device.
+// loadStartupConfig loads the provided startup config files into the SR Linux device in order.
 // It distinct between CLI- and JSON-styled configs and applies them accordingly.
+// The files are loaded into the candidate and committed once; when loading a file fails,
+// the candidate is discarded so that the device is left with the config it had before the load.
+// The config is committed and saved with a single command, so when that command fails the commit
+// may have succeeded; the config the device had before the load is then restored from the checkpoint
+// taken before the load, committed and saved.
+// Failures are returned as *startupConfigLoadError; session failures and commits failed
+// for reasons not related to the config are transient.
 func loadStartupConfig(
 	_ context.Context,
-	d *network.Driver,
-	fileName string,
+	d configSession,
+	f *srlFeatures,
+	fileNames []string,
 	log logr.Logger,
 ) error {
-	cmds := createStartupLoadCmds(fileName, defaultConfigPath)
-
-	r, err := d.SendConfigs(cmds)
+	cmds, err := createStartupLoadCmds(f, fileNames, defaultConfigPath)
 	if err != nil {
-		log.Error(err, "failed to send commands")
-
 		return err
 	}
 
-	if r.Failed != nil {
-		log.Error(r.Failed, "applying commands failed")
+	// the checkpoint of the first attempt is kept, the failed attempts restore the config it holds
+	if err := createCheckpoint(d, f, preLoadCheckpoint); err != nil {
+		return &startupConfigLoadError{
+			cmd:       fmt.Sprintf(f.checkpointCmd, preLoadCheckpoint),
+			transient: true,
+			err:       fmt.Errorf("checkpointing the config before the load: %w", err),
+		}
+	}
+
+	var lerr *startupConfigLoadError
+
+	r, err := d.SendConfigs(cmds, opoptions.WithStopOnFailed())
 
-		return r.Failed
+	switch {
+	case err != nil:
+		lerr = &startupConfigLoadError{transient: true, err: err}
+	case r.Failed != nil:
+		lerr = &startupConfigLoadError{err: r.Failed}
+
+		for _, resp := range r.Responses {
+			if resp.Failed != nil {
+				lerr.cmd, lerr.output = resp.Input, resp.Result
+				lerr.transient = resp.Input == f.saveCmd && commitFailureTransient(resp.Result)
+
+				break
+			}
+		}
+	default:
+		return nil
 	}
 
-	return nil
+	// the session failed or the commit and save command failed, the loaded config may be committed
+	if lerr.cmd == "" || lerr.cmd == f.saveCmd {
+		log.Error(lerr, "committing the startup config failed, restoring the config the node had before the load")
+
+		if rerr := revertToCheckpoint(d, f, preLoadCheckpoint, log); rerr != nil {
+			log.Error(rerr, "failed to restore the config the node had before the load")
+		}
+
+		return lerr
+	}
+
+	log.Error(lerr, "applying commands failed, discarding candidate")
+
+	dr, derr := d.SendConfigs([]string{f.discardCmd})
+	if derr == nil {
+		derr = dr.Failed
+	}
+
+	if derr != nil {
+		log.Error(derr, "failed to discard candidate")
+	}
+
+	return lerr
 }
 
 // createInitCheckpoint creates a checkpoint named "initial".
@@ -174,64 +292,82 @@ func loadStartupConfig(
 func createInitCheckpoint(
 	_ context.Context,
 	d *network.Driver,
+	f *srlFeatures,
 	log logr.Logger,
 ) error {
 	log.Info("Creating initial checkpoint...")
 
 	// sometimes status of srlinux cr is not updated immediately,
 	// resulting in several attempts to load configuration and create checkpoint
-	// so we need to check if the checkpoint already exists and bail out if so
-	checkCheckpointCmd := "info from state system configuration checkpoint *"
-
-	r, err := d.SendCommand(checkCheckpointCmd)
-	if err != nil {
-		log.Error(err, "failed to send command")
+	// so the checkpoint is created only if it doesn't exist yet
+	if err := createCheckpoint(d, f, initCheckpoint); err != nil {
+		log.Error(err, "failed to create checkpoint")
 
 		return err
 	}
 
-	if strings.Contains(r.Result, "initial") {
-		log.Info("initial checkpoint already exists, skipping")
+	return nil
+}
 
-		return nil
+// createCheckpoint creates the named checkpoint unless it already exists on the node.
+func createCheckpoint(d commandSession, f *srlFeatures, name string) error {
+	exists, err := hasCheckpoint(d, f, name)
+	if err != nil || exists {
+		return err
 	}
 
-	cmd := "/tools system configuration generate-checkpoint name initial"
-
-	r, err = d.SendCommand(cmd)
+	r, err := d.SendCommand(fmt.Sprintf(f.checkpointCmd, name))
 	if err != nil {
-		log.Error(err, "failed to send command")
-
 		return err
 	}
 
-	if r.Failed != nil {
-		log.Error(r.Failed, "applying command failed")
+	return r.Failed
+}
+
+// hasInitCheckpoint checks if the checkpoint named "initial" exists on the node.
+func hasInitCheckpoint(d commandSession, f *srlFeatures) (bool, error) {
+	return hasCheckpoint(d, f, initCheckpoint)
+}
 
-		return err
+// hasCheckpoint checks if the named checkpoint exists on the node.
+func hasCheckpoint(d commandSession, f *srlFeatures, name string) (bool, error) {
+	r, err := d.SendCommand(f.getCheckpointsCmd)
+	if err != nil {
+		return false, err
 	}
 
-	return nil
+	return strings.Contains(r.Result, name), nil
 }
 
 // createStartupLoadCmds creates the commands to be sent to the device based on the extension of the
-// provided startup config. It supports CLI- and JSON-styled configs.
-// After loading the configuration it saves it to the startup config.
-func createStartupLoadCmds(fileName, path string) []string {
-	ext := filepath.Ext(fileName)
-
-	var cmds []string
-
-	switch ext {
-	case ".json":
-		cmds = append(cmds, fmt.Sprintf("load file %s/%s", path, fileName))
-	case ".cli":
-		cmds = append(cmds, fmt.Sprintf("source %s/%s", path, fileName))
+// provided startup config files. It supports CLI- and JSON-styled configs,
+// configs of other styles are converted when stored in the startup config secret.
+// The first JSON-styled file replaces the candidate when it is loaded first,
+// the JSON-styled files loaded after other files are merged into the candidate.
+// After loading the configuration files in order it commits and saves them to the startup config.
+// The commands are taken from the SR Linux features matching the node version.
+func createStartupLoadCmds(f *srlFeatures, fileNames []string, path string) ([]string, error) {
+	cmds := make([]string, 0, len(fileNames)+1)
+
+	for i, fileName := range fileNames {
+		switch configFormat(fileName) {
+		case configFormatJSON:
+			loadCmd := f.loadJSONCmd
+			if i > 0 {
+				loadCmd = f.mergeJSONCmd
+			}
+
+			cmds = append(cmds, fmt.Sprintf(loadCmd, path+"/"+fileName))
+		case configFormatCLI:
+			cmds = append(cmds, fmt.Sprintf(f.loadCLICmd, path+"/"+fileName))
+		default:
+			return nil, fmt.Errorf("%w of %s, expected a .json or .cli file", ErrUnknownConfigFormat, fileName)
+		}
 	}
 
-	cmds = append(cmds, "commit save")
+	cmds = append(cmds, f.saveCmd)
 
-	return cmds
+	return cmds, nil
 }
 
 // podIPReady checks if the pod IP is assigned and sets the startup config phase to "pending" if not.
@@ -270,10 +406,12 @@ func (r *SrlinuxReconciler) getPodIP(ctx context.Context, srlinux *srlinuxv1.Srl
 	return pod.Status.PodIP
 }
 
-// waitNetworkReady checks if the network driver is ready and returns it.
+// waitNetworkReady checks if the network driver to the pod with the given IP is ready and returns it.
+// The returned driver is released with releaseNetworkDriver.
 func (r *SrlinuxReconciler) waitNetworkReady(
 	ctx context.Context,
 	log logr.Logger,
+	pod *corev1.Pod,
 	podIP string,
 ) *network.Driver {
 	timeout := time.After(podIPReadyTimeout)
@@ -290,7 +428,7 @@ func (r *SrlinuxReconciler) waitNetworkReady(
 		case <-tick.C:
 			log.Info("waiting for network readiness...")
 
-			d := r.getNetworkDriver(ctx, log, podIP)
+			d := r.Sessions.acquire(ctx, log, nodeOf(pod), pod.UID, podIP)
 			if d != nil {
 				log.Info("network ready")
 
@@ -300,8 +438,26 @@ func (r *SrlinuxReconciler) waitNetworkReady(
 	}
 }
 
-// getNetworkDriver returns the opened network driver for a given pod IP.
-func (*SrlinuxReconciler) getNetworkDriver(_ context.Context, log logr.Logger, podIP string) *network.Driver {
+// getNetworkDriver returns the opened network driver to the pod, reusing an idle session to it if any.
+// The returned driver is released with releaseNetworkDriver.
+func (r *SrlinuxReconciler) getNetworkDriver(ctx context.Context, log logr.Logger, pod *corev1.Pod) *network.Driver {
+	return r.Sessions.acquire(ctx, log, nodeOf(pod), pod.UID, pod.Status.PodIP)
+}
+
+// releaseNetworkDriver returns the driver got with getNetworkDriver to the session pool for reuse.
+// The driver is closed instead when err, the error of the last operation over it, is not nil.
+func (r *SrlinuxReconciler) releaseNetworkDriver(log logr.Logger, d *network.Driver, err error) {
+	r.Sessions.release(log, d, err)
+}
+
+// nodeOf returns the namespaced name of the node running in the pod.
+func nodeOf(pod *corev1.Pod) types.NamespacedName {
+	return types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}
+}
+
+// openNetworkDriver opens the network driver to the SR Linux node with the given management IP.
+// Nil is returned if the driver couldn't be opened.
+func openNetworkDriver(log logr.Logger, podIP string) *network.Driver {
 	p, err := platform.NewPlatform(
 		"nokia_srl",
 		podIP,
@@ -0,0 +1,168 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// startupConfigMaxAttempts is the number of attempts made to load the startup config
+	// when it fails for a transient reason.
+	startupConfigMaxAttempts = 5
+	// startupConfigRetryBackoff is the delay before the first retry, doubled with every attempt.
+	startupConfigRetryBackoff = 10 * time.Second
+	// startupConfigMaxRetryBackoff caps the delay between the retries.
+	startupConfigMaxRetryBackoff = 5 * time.Minute
+	// deviceErrorMaxLen limits the length of the device error kept in the status.
+	deviceErrorMaxLen = 512
+)
+
+var ErrNetworkNotReady = errors.New("network driver could not be opened")
+
+var (
+	// commitConfigErrorMarkers are the fragments of a failed commit output that indicate
+	// the config itself is rejected, e.g. it doesn't pass validation.
+	commitConfigErrorMarkers = []string{ //nolint:gochecknoglobals
+		"validation", "invalid", "parse", "parsing", "unknown", "mandatory", "leafref", "not allowed",
+	}
+	// commitTransientMarkers are the fragments of a failed commit output that indicate
+	// the commit failed for reasons not related to the config, e.g. a concurrent session.
+	commitTransientMarkers = []string{ //nolint:gochecknoglobals
+		"in progress", "lock", "busy", "timed out", "timeout", "connection", "unavailable", "try again",
+	}
+)
+
+// startupConfigLoadError is an error loading the startup config on the node.
+type startupConfigLoadError struct {
+	// cmd is the command that failed, empty when the failure is not related to a command.
+	cmd string
+	// output is the output of the failed command.
+	output string
+	// transient is true if the failure is not caused by the config and is worth retrying,
+	// e.g. a failed SSH session or a commit rejected by a concurrent session.
+	transient bool
+	err       error
+}
+
+func (e *startupConfigLoadError) Error() string {
+	if e.cmd == "" {
+		return e.err.Error()
+	}
+
+	return fmt.Sprintf("%q: %v", e.cmd, e.err)
+}
+
+func (e *startupConfigLoadError) Unwrap() error {
+	return e.err
+}
+
+// isTransient returns true if the startup config failure is worth retrying.
+func isTransient(err error) bool {
+	var lerr *startupConfigLoadError
+
+	return errors.As(err, &lerr) && lerr.transient
+}
+
+// commitFailureTransient checks if the output of a failed commit indicates a failure
+// not related to the config, such as a lock held by a concurrent session or a lost connection.
+// Commits rejected because of validation or parse errors are not transient.
+func commitFailureTransient(output string) bool {
+	out := strings.ToLower(output)
+	contains := func(m string) bool { return strings.Contains(out, m) }
+
+	if slices.ContainsFunc(commitConfigErrorMarkers, contains) {
+		return false
+	}
+
+	return slices.ContainsFunc(commitTransientMarkers, contains)
+}
+
+// startupConfigRetryWait returns the time left until the next attempt to load the startup config.
+func startupConfigRetryWait(srlinux *srlinuxv1.Srlinux, now time.Time) time.Duration {
+	next := srlinux.Status.StartupConfig.NextRetryTime
+	if next == nil {
+		return 0
+	}
+
+	return max(next.Sub(now), 0)
+}
+
+// startupConfigBackoff returns the delay before the retry following the given number of attempts.
+func startupConfigBackoff(attempts int32) time.Duration {
+	d := startupConfigRetryBackoff
+
+	for i := int32(1); i < attempts && d < startupConfigMaxRetryBackoff; i++ {
+		d *= 2
+	}
+
+	return min(d, startupConfigMaxRetryBackoff)
+}
+
+// recordStartupConfigAttempt records the outcome of an attempt to load the startup config.
+// A transient failure is retried with backoff until the attempts are exhausted, other failures fail the startup config.
+// The returned duration is the time after which the startup config is to be retried, 0 means never.
+func recordStartupConfigAttempt(
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	phase string,
+	err error,
+	now time.Time,
+) time.Duration {
+	st := &srlinux.Status.StartupConfig
+
+	st.Attempts++
+	st.LastAttemptTime = &metav1.Time{Time: now}
+	st.NextRetryTime = nil
+	st.Message, st.FailedCommand, st.DeviceError = "", "", ""
+	*update = true
+
+	if err == nil {
+		st.Phase = phase
+
+		return 0
+	}
+
+	st.Message = err.Error()
+
+	var lerr *startupConfigLoadError
+	if errors.As(err, &lerr) {
+		st.FailedCommand = lerr.cmd
+		st.DeviceError = truncate(lerr.output, deviceErrorMaxLen)
+	}
+
+	if !isTransient(err) || st.Attempts >= startupConfigMaxAttempts {
+		st.Phase = "failed"
+
+		return 0
+	}
+
+	backoff := startupConfigBackoff(st.Attempts)
+
+	log.Info("startup config failed for a transient reason, retrying", "attempt", st.Attempts, "backoff", backoff)
+
+	st.Phase = "retrying"
+	st.NextRetryTime = &metav1.Time{Time: now.Add(backoff)}
+
+	return backoff
+}
+
+// truncate truncates s to n bytes, marking the truncation.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n] + "...(truncated)"
+}
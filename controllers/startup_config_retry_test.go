@@ -0,0 +1,180 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestRecordStartupConfigAttempt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	errCommit := errors.New("commit failed")
+	errLoad := errors.New("parsing error")
+
+	tests := []struct {
+		desc        string
+		status      srlinuxv1.StartupConfigStatus
+		phase       string
+		err         error
+		wantStatus  srlinuxv1.StartupConfigStatus
+		wantRequeue time.Duration
+	}{
+		{
+			desc:  "loaded",
+			phase: "loaded",
+			status: srlinuxv1.StartupConfigStatus{
+				Phase:         "retrying",
+				Attempts:      1,
+				Message:       "network driver could not be opened",
+				NextRetryTime: &metav1.Time{Time: now},
+			},
+			wantStatus: srlinuxv1.StartupConfigStatus{
+				Phase:           "loaded",
+				Attempts:        2,
+				LastAttemptTime: &metav1.Time{Time: now},
+			},
+		},
+		{
+			desc: "config error is not retried",
+			err: &startupConfigLoadError{
+				cmd:    "load file /tmp/startup-config/config.json",
+				output: "Error: parsing error",
+				err:    errLoad,
+			},
+			wantStatus: srlinuxv1.StartupConfigStatus{
+				Phase:           "failed",
+				Attempts:        1,
+				LastAttemptTime: &metav1.Time{Time: now},
+				Message:         `"load file /tmp/startup-config/config.json": parsing error`,
+				FailedCommand:   "load file /tmp/startup-config/config.json",
+				DeviceError:     "Error: parsing error",
+			},
+		},
+		{
+			desc:   "commit failure is retried with backoff",
+			status: srlinuxv1.StartupConfigStatus{Phase: "retrying", Attempts: 2},
+			err: &startupConfigLoadError{
+				cmd:       "commit save",
+				output:    strings.Repeat("x", deviceErrorMaxLen+1),
+				transient: true,
+				err:       errCommit,
+			},
+			wantStatus: srlinuxv1.StartupConfigStatus{
+				Phase:           "retrying",
+				Attempts:        3,
+				LastAttemptTime: &metav1.Time{Time: now},
+				NextRetryTime:   &metav1.Time{Time: now.Add(40 * time.Second)},
+				Message:         `"commit save": commit failed`,
+				FailedCommand:   "commit save",
+				DeviceError:     strings.Repeat("x", deviceErrorMaxLen) + "...(truncated)",
+			},
+			wantRequeue: 40 * time.Second,
+		},
+		{
+			desc:   "attempts exhausted",
+			status: srlinuxv1.StartupConfigStatus{Phase: "retrying", Attempts: startupConfigMaxAttempts - 1},
+			err:    &startupConfigLoadError{transient: true, err: ErrNetworkNotReady},
+			wantStatus: srlinuxv1.StartupConfigStatus{
+				Phase:           "failed",
+				Attempts:        startupConfigMaxAttempts,
+				LastAttemptTime: &metav1.Time{Time: now},
+				Message:         ErrNetworkNotReady.Error(),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			update := false
+			srl := &srlinuxv1.Srlinux{Status: srlinuxv1.SrlinuxStatus{StartupConfig: tt.status}}
+
+			requeue := recordStartupConfigAttempt(ctrl.Log, &update, srl, tt.phase, tt.err, now)
+
+			if !update || requeue != tt.wantRequeue {
+				t.Fatalf("%s: unexpected update %v or requeue %v", tt.desc, update, requeue)
+			}
+
+			if !cmp.Equal(srl.Status.StartupConfig, tt.wantStatus) {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc, srl.Status.StartupConfig, tt.wantStatus)
+			}
+		},
+		)
+	}
+}
+
+func TestCommitFailureTransient(t *testing.T) {
+	tests := []struct {
+		desc   string
+		output string
+		want   bool
+	}{
+		{
+			desc:   "commit in progress in another session",
+			output: "Error: Another commit is in progress, try again later",
+			want:   true,
+		},
+		{
+			desc:   "datastore locked",
+			output: "Error: The candidate datastore is locked by another session",
+			want:   true,
+		},
+		{
+			desc:   "validation error",
+			output: "Error: Failed to commit. Errors:\nValidation error: interface ethernet-1/1 subinterface 0: ipv4 must be enabled",
+			want:   false,
+		},
+		{
+			desc:   "parse error",
+			output: "Error: Parsing error: Unknown token 'hostname'",
+			want:   false,
+		},
+		{
+			desc:   "validation error mentioning a timeout leaf",
+			output: "Error: Failed to commit. Errors:\nvalue 0 of hold-timeout is invalid",
+			want:   false,
+		},
+		{
+			desc:   "unrecognized failure",
+			output: "Error: Failed to commit",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := commitFailureTransient(tt.output); got != tt.want {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %v\nexpected:%v", tt.desc, got, tt.want)
+			}
+		},
+		)
+	}
+}
+
+func TestStartupConfigBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{attempts: 1, want: 10 * time.Second},
+		{attempts: 2, want: 20 * time.Second},
+		{attempts: 4, want: 80 * time.Second},
+		{attempts: 20, want: startupConfigMaxRetryBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := startupConfigBackoff(tt.attempts); got != tt.want {
+			t.Fatalf("attempts %d: expected backoff %v, got %v", tt.attempts, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,750 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	configFormatJSON = "json"
+	configFormatCLI  = "cli"
+	configFormatYAML = "yaml"
+
+	startupConfigFetchTimeout = 10 * time.Second
+	// renderedAtAnnotation records the time the templated startup config was last rendered with the pod IP.
+	renderedAtAnnotation = "kne.srlinux.dev/rendered-at"
+	// startupConfigMaxSize limits the size of the config fetched from a URL.
+	startupConfigMaxSize = 10 << 20
+	// startupConfigMaxRedirects limits the number of redirects followed when fetching the config from a URL.
+	startupConfigMaxRedirects = 5
+)
+
+var (
+	ErrStartupConfigSource = errors.New("invalid startup config source")
+	ErrUnknownConfigFormat = errors.New("unknown startup config format")
+	// ErrURLNotAllowed is returned for URL sources which host is not allowed by the controller.
+	ErrURLNotAllowed = errors.New("startup config URL is not allowed")
+	// ErrStartupConfigFetch is returned when a startup config source couldn't be fetched,
+	// e.g. the config map doesn't exist yet or the URL is not reachable. The fetch is retried.
+	ErrStartupConfigFetch = errors.New("startup config source could not be fetched")
+)
+
+// jsonListKeys are the names of the keys the items of JSON config lists are identified by,
+// e.g. "name" of interfaces and network instances and "index" of subinterfaces.
+//
+//nolint:gochecknoglobals
+var jsonListKeys = []string{"name", "index", "id", "sequence-id", "peer-address", "ip-prefix", "prefix"}
+
+// ParseURLHosts parses a comma separated list of hosts the startup config may be fetched from.
+// A host is either a host name or an IP, optionally with a port, or a `*.domain` wildcard matching subdomains.
+func ParseURLHosts(s string) []string {
+	var hosts []string
+
+	for _, h := range strings.Split(s, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+
+	return hosts
+}
+
+// cliKeywords are the keywords CLI-styled config lines start with.
+//
+//nolint:gochecknoglobals
+var cliKeywords = []string{"set", "delete", "insert", "replace", "enter", "exit", "commit", "discard", "/"}
+
+// startupConfigPart is a startup config fetched from a source.
+type startupConfigPart struct {
+	// origin describes where the part comes from, used in errors.
+	origin string
+	format string
+	data   []byte
+}
+
+// startupConfigSecretName returns the name of the Secret holding the startup config
+// merged from the startup config sources.
+func startupConfigSecretName(s *srlinuxv1.Srlinux) string {
+	return fmt.Sprintf("%s-startup-config", s.Name)
+}
+
+// configFormat returns the style of the config based on the extension of the file name.
+func configFormat(name string) string {
+	switch filepath.Ext(name) {
+	case ".json":
+		return configFormatJSON
+	case ".cli":
+		return configFormatCLI
+	case ".yaml", ".yml":
+		return configFormatYAML
+	}
+
+	return ""
+}
+
+// sniffConfigFormat returns the style of the config based on its content.
+// An empty string is returned when the style can't be determined.
+func sniffConfigFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+
+	switch {
+	case len(trimmed) == 0:
+		return ""
+	case trimmed[0] == '{' && json.Valid(trimmed):
+		return configFormatJSON
+	case isCLIConfig(trimmed):
+		return configFormatCLI
+	}
+
+	m := map[string]any{}
+	if err := yaml.Unmarshal(trimmed, &m); err == nil && len(m) > 0 {
+		return configFormatYAML
+	}
+
+	return ""
+}
+
+// isCLIConfig returns true if every line of the config, except comments and empty lines,
+// starts with a CLI keyword.
+func isCLIConfig(data []byte) bool {
+	for _, l := range strings.Split(string(data), "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		if !slices.ContainsFunc(cliKeywords, func(k string) bool {
+			return l == k || strings.HasPrefix(l, k+" ") || (k == "/" && strings.HasPrefix(l, k))
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizeStartupConfigPart determines the style of the part by its content if the extension
+// didn't tell it, and converts YAML-styled parts to JSON, so that only JSON- and CLI-styled parts are loaded.
+func normalizeStartupConfigPart(p startupConfigPart) (startupConfigPart, error) {
+	if p.format == "" {
+		p.format = sniffConfigFormat(p.data)
+	}
+
+	switch p.format {
+	case configFormatJSON, configFormatCLI:
+		return p, nil
+	case configFormatYAML:
+		data, err := yaml.YAMLToJSON(p.data)
+		if err != nil {
+			return p, fmt.Errorf("%w: %s: %v", ErrStartupConfigSource, p.origin, err) //nolint:errorlint
+		}
+
+		p.format, p.data = configFormatJSON, data
+
+		return p, nil
+	}
+
+	return p, fmt.Errorf("%w of %s, expected JSON-, YAML- or CLI-styled config", ErrUnknownConfigFormat, p.origin)
+}
+
+// usesStartupConfigSecret returns true if the startup config is mounted to the pod from the startup config Secret,
+// that is when the startup config sources are set or when the config provided by kne needs to be converted
+// before it can be loaded.
+func usesStartupConfigSecret(s *srlinuxv1.Srlinux) bool {
+	cfg := s.Spec.GetConfig()
+
+	return s.Spec.StartupConfig != nil ||
+		(cfg.ConfigDataPresent && !isLoadableFormat(configFormat(cfg.ConfigFile)))
+}
+
+// isLoadableFormat returns true if the config style can be loaded on the node as is.
+func isLoadableFormat(format string) bool {
+	return format == configFormatJSON || format == configFormatCLI
+}
+
+// createStartupConfigSecret fetches the startup config sources, merges them and stores
+// the merged config in the <name>-startup-config Secret owned by the Srlinux object.
+// The config provided by kne, if present, is merged first.
+// The Secret is mounted to the pod instead of the kne config map.
+// Templated sources are rendered without the pod IP, as the pod is not created yet;
+// they are rendered again once the pod IP is known, see syncRenderedStartupConfig.
+func (r *SrlinuxReconciler) createStartupConfigSecret(
+	ctx context.Context,
+	s *srlinuxv1.Srlinux,
+	log logr.Logger,
+) error {
+	if !usesStartupConfigSecret(s) {
+		return nil
+	}
+
+	data, err := r.renderStartupConfig(ctx, s, "")
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+
+	err = r.Get(ctx, types.NamespacedName{Name: startupConfigSecretName(s), Namespace: s.Namespace}, secret)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	if k8serrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      startupConfigSecretName(s),
+				Namespace: s.Namespace,
+			},
+			Data: data,
+		}
+
+		if err := ctrl.SetControllerReference(s, secret, r.Scheme); err != nil {
+			return err
+		}
+
+		log.Info("creating secret", "secret name", secret.Name)
+
+		return r.Create(ctx, secret)
+	}
+
+	log.Info("updating secret", "secret name", secret.Name)
+
+	secret.Data = data
+
+	return r.Update(ctx, secret)
+}
+
+// recordStartupConfigSourcesFailure records the failure to fetch or merge the startup config sources
+// before the pod is created. A source that couldn't be fetched, e.g. a config map not created yet
+// or a config server not reachable, is retried with backoff, and the time until the retry is returned.
+// Other failures fail the startup config and 0 is returned.
+func recordStartupConfigSourcesFailure(
+	log logr.Logger,
+	srlinux *srlinuxv1.Srlinux,
+	err error,
+	now time.Time,
+) time.Duration {
+	st := &srlinux.Status.StartupConfig
+	st.Message = err.Error()
+
+	if errors.Is(err, ErrStartupConfigFetch) {
+		st.Attempts++
+		backoff := startupConfigBackoff(st.Attempts)
+
+		log.Info("startup config sources couldn't be fetched, retrying", "attempt", st.Attempts, "backoff", backoff)
+
+		st.Phase = "retrying"
+		st.NextRetryTime = &metav1.Time{Time: now.Add(backoff)}
+
+		meta.SetStatusCondition(&srlinux.Status.Conditions, metav1.Condition{
+			Type:    srlinuxv1.ConditionStartupConfigSourcesFetched,
+			Status:  metav1.ConditionFalse,
+			Reason:  srlinuxv1.ReasonFetchFailed,
+			Message: err.Error(),
+		})
+
+		return backoff
+	}
+
+	srlinux.Status.Status = "error"
+	st.Phase = "failed"
+	st.NextRetryTime = nil
+
+	meta.SetStatusCondition(&srlinux.Status.Conditions, metav1.Condition{
+		Type:    srlinuxv1.ConditionStartupConfigSourcesFetched,
+		Status:  metav1.ConditionFalse,
+		Reason:  srlinuxv1.ReasonInvalidSources,
+		Message: err.Error(),
+	})
+
+	return 0
+}
+
+// syncRenderedStartupConfig renders the templated startup config with the pod IP and updates
+// the <name>-startup-config Secret if the rendered config differs from the one mounted to the pod.
+// The time left until kubelet syncs the updated Secret to the pod is returned.
+func (r *SrlinuxReconciler) syncRenderedStartupConfig(
+	ctx context.Context,
+	log logr.Logger,
+	s *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) (time.Duration, error) {
+	if s.Spec.StartupConfig == nil || !s.Spec.StartupConfig.Template {
+		return 0, nil
+	}
+
+	secret := &corev1.Secret{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: startupConfigSecretName(s), Namespace: s.Namespace}, secret)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := r.renderStartupConfig(ctx, s, pod.Status.PodIP)
+	if err != nil {
+		return 0, err
+	}
+
+	if !maps.EqualFunc(secret.Data, data, bytes.Equal) {
+		log.Info("updating rendered startup config", "secret name", secret.Name)
+
+		secret.Data = data
+
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+
+		secret.Annotations[renderedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+		if err := r.Update(ctx, secret); err != nil {
+			return 0, err
+		}
+	}
+
+	renderedAt, err := time.Parse(time.RFC3339, secret.Annotations[renderedAtAnnotation])
+	if err != nil || (pod.Status.StartTime != nil && pod.Status.StartTime.After(renderedAt)) {
+		// the config rendered before the pod started is already mounted
+		return 0, nil //nolint:nilerr
+	}
+
+	return max(time.Until(renderedAt.Add(secretVolumeSyncDelay)), 0), nil
+}
+
+// renderStartupConfig fetches the startup config sources, renders them if templating is enabled
+// and merges or layers them. The config files to be stored in the startup config Secret are returned.
+func (r *SrlinuxReconciler) renderStartupConfig(
+	ctx context.Context,
+	s *srlinuxv1.Srlinux,
+	podIP string,
+) (map[string][]byte, error) {
+	parts, err := r.fetchStartupConfigParts(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := s.Spec.GetStartupConfig()
+
+	if spec.Template {
+		data, err := r.startupConfigTemplateDataFor(ctx, s, podIP)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range parts {
+			if parts[i], err = renderStartupConfigPart(parts[i], data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i := range parts {
+		if parts[i], err = normalizeStartupConfigPart(parts[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.Layered {
+		return layerStartupConfig(parts)
+	}
+
+	fileName, data, err := mergeStartupConfig(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{fileName: data}, nil
+}
+
+// startupConfigFileNames returns the names of the startup config files mounted to the pod, in load order.
+func (r *SrlinuxReconciler) startupConfigFileNames(ctx context.Context, s *srlinuxv1.Srlinux) ([]string, error) {
+	if !usesStartupConfigSecret(s) {
+		return []string{s.Spec.GetConfig().ConfigFile}, nil
+	}
+
+	secret := &corev1.Secret{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: startupConfigSecretName(s), Namespace: s.Namespace}, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(secret.Data) == 0 {
+		return nil, fmt.Errorf("%w: secret %s holds no config", ErrStartupConfigSource, secret.Name)
+	}
+
+	// layered fragments are named so that their lexical order is the load order
+	return slices.Sorted(maps.Keys(secret.Data)), nil
+}
+
+// fetchStartupConfigParts fetches the config provided by kne and the startup config sources, in order.
+func (r *SrlinuxReconciler) fetchStartupConfigParts(
+	ctx context.Context,
+	s *srlinuxv1.Srlinux,
+) ([]startupConfigPart, error) {
+	var parts []startupConfigPart
+
+	if cfg := s.Spec.GetConfig(); cfg.ConfigDataPresent {
+		// kne creates the configmap with the name <node-name>-config
+		data, err := r.configMapData(ctx, s.Namespace, fmt.Sprintf("%s-config", s.Name), cfg.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, startupConfigPart{origin: "kne config", format: configFormat(cfg.ConfigFile), data: data})
+	}
+
+	sources := s.Spec.GetStartupConfig().Sources
+
+	for i := range sources {
+		p, err := r.fetchStartupConfigSource(ctx, s.Namespace, &sources[i])
+		if err != nil {
+			return nil, fmt.Errorf("source %d: %w", i, err)
+		}
+
+		parts = append(parts, p...)
+	}
+
+	return parts, nil
+}
+
+// fetchStartupConfigSource fetches the config from the source.
+func (r *SrlinuxReconciler) fetchStartupConfigSource(
+	ctx context.Context,
+	ns string,
+	src *srlinuxv1.StartupConfigSource,
+) ([]startupConfigPart, error) {
+	var parts []startupConfigPart
+
+	switch {
+	case src.Inline != nil:
+		parts = append(parts, startupConfigPart{origin: "inline", format: configFormatJSON, data: src.Inline.Raw})
+	case src.InlineCLI != "":
+		parts = append(parts, startupConfigPart{origin: "inline-cli", format: configFormatCLI, data: []byte(src.InlineCLI)})
+	case src.ConfigMap != nil:
+		for _, k := range src.ConfigMap.Keys {
+			data, err := r.configMapData(ctx, ns, src.ConfigMap.Name, k)
+			if err != nil {
+				return nil, err
+			}
+
+			parts = append(parts, startupConfigPart{
+				origin: fmt.Sprintf("config map %s key %s", src.ConfigMap.Name, k),
+				format: configFormat(k),
+				data:   data,
+			})
+		}
+	case src.Secret != nil:
+		for _, k := range src.Secret.Keys {
+			data, err := r.secretData(ctx, ns, src.Secret.Name, k)
+			if err != nil {
+				return nil, err
+			}
+
+			parts = append(parts, startupConfigPart{
+				origin: fmt.Sprintf("secret %s key %s", src.Secret.Name, k),
+				format: configFormat(k),
+				data:   data,
+			})
+		}
+	case src.URL != "":
+		data, err := fetchURL(ctx, src.URL, r.StartupConfigURLHosts)
+		if err != nil {
+			return nil, err
+		}
+
+		u, _ := url.Parse(src.URL)
+
+		parts = append(parts, startupConfigPart{origin: src.URL, format: configFormat(path.Base(u.Path)), data: data})
+	default:
+		return nil, fmt.Errorf("%w: no source is set", ErrStartupConfigSource)
+	}
+
+	return parts, nil
+}
+
+// configMapData returns the data of the config map key.
+func (r *SrlinuxReconciler) configMapData(ctx context.Context, ns, name, key string) ([]byte, error) {
+	cm := &corev1.ConfigMap{}
+
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, cm); err != nil {
+		return nil, fmt.Errorf("%w: config map %s: %w", ErrStartupConfigFetch, name, err)
+	}
+
+	if d, ok := cm.Data[key]; ok {
+		return []byte(d), nil
+	}
+
+	if d, ok := cm.BinaryData[key]; ok {
+		return d, nil
+	}
+
+	return nil, fmt.Errorf("%w: config map %s has no key %s", ErrStartupConfigSource, name, key)
+}
+
+// secretData returns the data of the secret key.
+func (r *SrlinuxReconciler) secretData(ctx context.Context, ns, name, key string) ([]byte, error) {
+	secret := &corev1.Secret{}
+
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, secret); err != nil {
+		return nil, fmt.Errorf("%w: secret %s: %w", ErrStartupConfigFetch, name, err)
+	}
+
+	d, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: secret %s has no key %s", ErrStartupConfigSource, name, key)
+	}
+
+	return d, nil
+}
+
+// fetchURL fetches the config from HTTP(S) URL which host is in the allowed hosts.
+// Redirects are followed only to the allowed hosts, and the config larger than startupConfigMaxSize is refused.
+func fetchURL(ctx context.Context, rawURL string, hosts []string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("%w: %q is not an HTTP(S) URL", ErrStartupConfigSource, rawURL)
+	}
+
+	if !urlHostAllowed(u, hosts) {
+		return nil, fmt.Errorf("%w: %s, allowed hosts: %v", ErrURLNotAllowed, u.Host, hosts)
+	}
+
+	c := &http.Client{
+		Timeout: startupConfigFetchTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= startupConfigMaxRedirects {
+				return fmt.Errorf("%w: too many redirects fetching %s", ErrStartupConfigSource, rawURL)
+			}
+
+			if !urlHostAllowed(req.URL, hosts) {
+				return fmt.Errorf("%w: %s redirects to %s", ErrURLNotAllowed, rawURL, req.URL.Host)
+			}
+
+			return nil
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		// the redirects to the hosts not allowed are refused, not retried
+		if errors.Is(err, ErrURLNotAllowed) || errors.Is(err, ErrStartupConfigSource) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrStartupConfigFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: fetching %s returned %s", ErrStartupConfigFetch, rawURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, startupConfigMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStartupConfigFetch, err)
+	}
+
+	if len(data) > startupConfigMaxSize {
+		return nil, fmt.Errorf("%w: config at %s exceeds %d bytes", ErrStartupConfigSource, rawURL, startupConfigMaxSize)
+	}
+
+	return data, nil
+}
+
+// urlHostAllowed checks if the URL host matches one of the allowed hosts.
+// A host without a port matches any port, a `*.domain` host matches the subdomains of the domain.
+func urlHostAllowed(u *url.URL, hosts []string) bool {
+	host := strings.ToLower(u.Host)
+	name := strings.ToLower(u.Hostname())
+
+	for _, h := range hosts {
+		switch {
+		case h == host || h == name:
+			return true
+		case strings.HasPrefix(h, "*.") && strings.HasSuffix(name, h[1:]):
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeStartupConfig merges the startup config parts into a single config.
+// JSON-styled parts are deep-merged, CLI-styled parts are concatenated.
+// The name of the file the merged config is to be stored in is returned along with the config.
+func mergeStartupConfig(parts []startupConfigPart) (string, []byte, error) {
+	if len(parts) == 0 {
+		return "", nil, fmt.Errorf("%w: no config", ErrStartupConfigSource)
+	}
+
+	format := parts[0].format
+
+	for _, p := range parts {
+		switch {
+		case p.format == "":
+			return "", nil, fmt.Errorf("%w: unknown config style of %s, expected .json or .cli",
+				ErrStartupConfigSource, p.origin)
+		case p.format != format:
+			return "", nil, fmt.Errorf("%w: %s is %s-styled while the preceding sources are %s-styled",
+				ErrStartupConfigSource, p.origin, p.format, format)
+		}
+	}
+
+	if format == configFormatCLI {
+		var b bytes.Buffer
+
+		for _, p := range parts {
+			b.Write(bytes.TrimRight(p.data, "\n"))
+			b.WriteByte('\n')
+		}
+
+		return "config.cli", b.Bytes(), nil
+	}
+
+	merged := map[string]any{}
+
+	for _, p := range parts {
+		cfg := map[string]any{}
+		if err := json.Unmarshal(p.data, &cfg); err != nil {
+			return "", nil, fmt.Errorf("%w: %s: %v", ErrStartupConfigSource, p.origin, err) //nolint:errorlint
+		}
+
+		mergeJSON(merged, cfg)
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "config.json", data, nil
+}
+
+// layerStartupConfig names the startup config parts as fragments loaded in order.
+// The fragment names are prefixed with the position of the part, e.g. 000-config.json, 001-config.cli.
+func layerStartupConfig(parts []startupConfigPart) (map[string][]byte, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: no config", ErrStartupConfigSource)
+	}
+
+	fragments := make(map[string][]byte, len(parts))
+
+	for i, p := range parts {
+		if p.format == "" {
+			return nil, fmt.Errorf("%w: unknown config style of %s, expected .json or .cli",
+				ErrStartupConfigSource, p.origin)
+		}
+
+		fragments[fmt.Sprintf("%03d-config.%s", i, p.format)] = p.data
+	}
+
+	return fragments, nil
+}
+
+// mergeJSON deep-merges src into dst. Objects are merged, lists of objects identified by a key
+// (see jsonListKeys) are merged by the key, other values, including other lists, are replaced.
+func mergeJSON(dst, src map[string]any) {
+	for k, v := range src {
+		switch sv := v.(type) {
+		case map[string]any:
+			if dm, ok := dst[k].(map[string]any); ok {
+				mergeJSON(dm, sv)
+
+				continue
+			}
+		case []any:
+			if dl, ok := dst[k].([]any); ok {
+				if merged, ok := mergeJSONList(dl, sv); ok {
+					dst[k] = merged
+
+					continue
+				}
+			}
+		}
+
+		dst[k] = v
+	}
+}
+
+// mergeJSONList merges the items of the src list into the dst list by the key both lists are identified by.
+// The items of src with the key of a dst item are merged into it, the others are appended.
+// False is returned when the lists are not identified by the same key.
+func mergeJSONList(dst, src []any) ([]any, bool) {
+	key := jsonListKey(slices.Concat(dst, src))
+	if key == "" {
+		return nil, false
+	}
+
+	merged := slices.Clone(dst)
+
+	for _, item := range src {
+		sm := item.(map[string]any) //nolint:forcetypeassert
+
+		i := slices.IndexFunc(merged, func(d any) bool {
+			return fmt.Sprint(d.(map[string]any)[key]) == fmt.Sprint(sm[key]) //nolint:forcetypeassert
+		})
+		if i < 0 {
+			merged = append(merged, sm)
+
+			continue
+		}
+
+		mergeJSON(merged[i].(map[string]any), sm) //nolint:forcetypeassert
+	}
+
+	return merged, true
+}
+
+// jsonListKey returns the key every item of the list is an object with, or an empty string if there is none.
+func jsonListKey(items []any) string {
+	for _, key := range jsonListKeys {
+		if !slices.ContainsFunc(items, func(item any) bool {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return true
+			}
+
+			switch m[key].(type) {
+			case string, float64:
+				return false
+			}
+
+			return true
+		}) {
+			return key
+		}
+	}
+
+	return ""
+}
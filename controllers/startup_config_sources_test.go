@@ -0,0 +1,578 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFetchAndMergeStartupConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/configs/system.json":
+			_, _ = w.Write([]byte(`{"system": {"name": {"host-name": "srl1"}}}`))
+		case "/configs/banner.cli":
+			_, _ = w.Write([]byte("set / system banner login-banner lab\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	srvURL, _ := url.Parse(srv.URL)
+
+	clientObjs := []runtime.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: defaultNamespace},
+			Data: map[string]string{
+				"interfaces.json": `{"interface": [{"name": "ethernet-1/1"}], "system": {"gnmi-server": {}}}`,
+				"acl.cli":         "set / acl",
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: defaultNamespace},
+			Data: map[string][]byte{
+				"users.json": []byte(`{"system": {"aaa": {"authentication": {"admin-user": {"password": "s3cr3t"}}}}}`),
+			},
+		},
+	}
+
+	tests := []struct {
+		desc     string
+		sources  []srlinuxv1.StartupConfigSource
+		wantFile string
+		wantData string
+		err      error
+	}{
+		{
+			desc: "JSON sources deep-merged in order",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"interfaces.json"}}},
+				{Secret: &srlinuxv1.ConfigKeySelector{Name: "creds", Keys: []string{"users.json"}}},
+				{URL: srv.URL + "/configs/system.json"},
+				{Inline: &runtime.RawExtension{Raw: []byte(`{"system": {"name": {"host-name": "r1"}}}`)}},
+			},
+			wantFile: "config.json",
+			wantData: `{
+  "interface": [
+    {
+      "name": "ethernet-1/1"
+    }
+  ],
+  "system": {
+    "aaa": {
+      "authentication": {
+        "admin-user": {
+          "password": "s3cr3t"
+        }
+      }
+    },
+    "gnmi-server": {},
+    "name": {
+      "host-name": "r1"
+    }
+  }
+}`,
+		},
+		{
+			desc: "CLI sources concatenated in order",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"acl.cli"}}},
+				{URL: srv.URL + "/configs/banner.cli"},
+				{InlineCLI: "set / system name host-name r1"},
+			},
+			wantFile: "config.cli",
+			wantData: "set / acl\nset / system banner login-banner lab\nset / system name host-name r1\n",
+		},
+		{
+			desc: "mixed config styles",
+			sources: []srlinuxv1.StartupConfigSource{
+				{InlineCLI: "set / system name host-name r1"},
+				{Inline: &runtime.RawExtension{Raw: []byte(`{}`)}},
+			},
+			err: ErrStartupConfigSource,
+		},
+		{
+			desc: "missing key",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"missing.json"}}},
+			},
+			err: ErrStartupConfigSource,
+		},
+		{
+			desc: "keyed lists merged by key",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"interfaces.json"}}},
+				{Inline: &runtime.RawExtension{Raw: []byte(`{"interface": [
+					{"name": "ethernet-1/1", "admin-state": "enable", "subinterface": [{"index": 0}]},
+					{"name": "ethernet-1/2"}
+				]}`)}},
+			},
+			wantFile: "config.json",
+			wantData: `{
+  "interface": [
+    {
+      "admin-state": "enable",
+      "name": "ethernet-1/1",
+      "subinterface": [
+        {
+          "index": 0
+        }
+      ]
+    },
+    {
+      "name": "ethernet-1/2"
+    }
+  ],
+  "system": {
+    "gnmi-server": {}
+  }
+}`,
+		},
+		{
+			desc: "URL not found",
+			sources: []srlinuxv1.StartupConfigSource{
+				{URL: srv.URL + "/configs/missing.json"},
+			},
+			err: ErrStartupConfigFetch,
+		},
+		{
+			desc: "config map not found",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "missing", Keys: []string{"config.json"}}},
+			},
+			err: ErrStartupConfigFetch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &SrlinuxReconciler{
+				Client:                fake.NewClientBuilder().WithRuntimeObjects(clientObjs...).Build(),
+				Scheme:                scheme.Scheme,
+				StartupConfigURLHosts: []string{srvURL.Host},
+			}
+
+			srl := &srlinuxv1.Srlinux{
+				ObjectMeta: metav1.ObjectMeta{Name: defaultCRName, Namespace: defaultNamespace},
+				Spec: srlinuxv1.SrlinuxSpec{
+					StartupConfig: &srlinuxv1.StartupConfigSpec{Sources: tt.sources},
+				},
+			}
+
+			parts, err := r.fetchStartupConfigParts(ctx, srl)
+			if err == nil {
+				var (
+					file string
+					data []byte
+				)
+
+				file, data, err = mergeStartupConfig(parts)
+				if err == nil && (file != tt.wantFile || !cmp.Equal(string(data), tt.wantData)) {
+					t.Fatalf("%s: unexpected merged config %s\n%s", tt.desc, file, cmp.Diff(tt.wantData, string(data)))
+				}
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+		},
+		)
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	tests := []struct {
+		desc string
+		dst  string
+		src  string
+		want string
+	}{
+		{
+			desc: "objects merged",
+			dst:  `{"system": {"name": {"host-name": "r1"}, "banner": {}}}`,
+			src:  `{"system": {"name": {"host-name": "r2"}}}`,
+			want: `{"system": {"name": {"host-name": "r2"}, "banner": {}}}`,
+		},
+		{
+			desc: "keyed list items merged by key and appended",
+			dst:  `{"network-instance": [{"name": "default", "interface": [{"name": "ethernet-1/1.0"}]}]}`,
+			src:  `{"network-instance": [{"name": "default", "interface": [{"name": "ethernet-1/2.0"}]}, {"name": "mgmt"}]}`,
+			want: `{"network-instance": [
+				{"name": "default", "interface": [{"name": "ethernet-1/1.0"}, {"name": "ethernet-1/2.0"}]},
+				{"name": "mgmt"}
+			]}`,
+		},
+		{
+			desc: "lists of values replaced",
+			dst:  `{"system": {"dns": {"server-list": ["1.1.1.1"]}}}`,
+			src:  `{"system": {"dns": {"server-list": ["8.8.8.8"]}}}`,
+			want: `{"system": {"dns": {"server-list": ["8.8.8.8"]}}}`,
+		},
+		{
+			desc: "lists without a common key replaced",
+			dst:  `{"entries": [{"name": "a"}]}`,
+			src:  `{"entries": [{"sequence-id": 10}]}`,
+			want: `{"entries": [{"sequence-id": 10}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var dst, src, want map[string]any
+
+			for i, s := range []string{tt.dst, tt.src, tt.want} {
+				if err := json.Unmarshal([]byte(s), []*map[string]any{&dst, &src, &want}[i]); err != nil {
+					t.Fatalf("%s: invalid test JSON: %v", tt.desc, err)
+				}
+			}
+
+			mergeJSON(dst, src)
+
+			if !cmp.Equal(dst, want) {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v", tt.desc, dst, want)
+			}
+		},
+		)
+	}
+}
+
+func TestRecordStartupConfigSourcesFailure(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc        string
+		err         error
+		attempts    int32
+		wantPhase   string
+		wantReason  string
+		wantRequeue time.Duration
+	}{
+		{
+			desc:        "fetch failure retried with backoff",
+			err:         fmt.Errorf("source 0: %w: config map base: not found", ErrStartupConfigFetch),
+			attempts:    1,
+			wantPhase:   "retrying",
+			wantReason:  srlinuxv1.ReasonFetchFailed,
+			wantRequeue: 2 * startupConfigRetryBackoff,
+		},
+		{
+			desc:       "invalid source fails",
+			err:        fmt.Errorf("source 0: %w: no source is set", ErrStartupConfigSource),
+			wantPhase:  "failed",
+			wantReason: srlinuxv1.ReasonInvalidSources,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			srl := &srlinuxv1.Srlinux{
+				Status: srlinuxv1.SrlinuxStatus{StartupConfig: srlinuxv1.StartupConfigStatus{Attempts: tt.attempts}},
+			}
+
+			requeue := recordStartupConfigSourcesFailure(ctrl.Log, srl, tt.err, now)
+
+			c := meta.FindStatusCondition(srl.Status.Conditions, srlinuxv1.ConditionStartupConfigSourcesFetched)
+			if requeue != tt.wantRequeue || srl.Status.StartupConfig.Phase != tt.wantPhase ||
+				c == nil || c.Status != metav1.ConditionFalse || c.Reason != tt.wantReason {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %v %s %+v\nexpected:%v %s %s",
+					tt.desc, requeue, srl.Status.StartupConfig.Phase, c, tt.wantRequeue, tt.wantPhase, tt.wantReason)
+			}
+		},
+		)
+	}
+}
+
+func TestFetchURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config.json":
+			_, _ = w.Write([]byte(`{}`))
+		case "/large.json":
+			_, _ = w.Write([]byte(strings.Repeat(" ", startupConfigMaxSize+1)))
+		case "/metadata":
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+
+	tests := []struct {
+		desc  string
+		url   string
+		hosts []string
+		err   error
+	}{
+		{
+			desc:  "allowed host",
+			url:   srv.URL + "/config.json",
+			hosts: []string{u.Host},
+		},
+		{
+			desc:  "allowed host name with any port",
+			url:   srv.URL + "/config.json",
+			hosts: []string{u.Hostname()},
+		},
+		{
+			desc: "no hosts allowed",
+			url:  srv.URL + "/config.json",
+			err:  ErrURLNotAllowed,
+		},
+		{
+			desc:  "metadata endpoint not allowed",
+			url:   "http://169.254.169.254/latest/meta-data/",
+			hosts: []string{u.Host, "*.example.com"},
+			err:   ErrURLNotAllowed,
+		},
+		{
+			desc:  "redirect to a host not allowed",
+			url:   srv.URL + "/metadata",
+			hosts: []string{u.Host},
+			err:   ErrURLNotAllowed,
+		},
+		{
+			desc:  "config too large",
+			url:   srv.URL + "/large.json",
+			hosts: []string{u.Host},
+			err:   ErrStartupConfigSource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := fetchURL(ctx, tt.url, tt.hosts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+		},
+		)
+	}
+}
+
+func TestURLHostAllowed(t *testing.T) {
+	hosts := ParseURLHosts(" config-server.lab.svc, *.Example.com ,10.0.0.1:8080,")
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{url: "http://config-server.lab.svc/srl1.json", want: true},
+		{url: "http://config-server.lab.svc:8080/srl1.json", want: true},
+		{url: "https://configs.example.com/srl1.json", want: true},
+		{url: "https://example.com/srl1.json", want: false},
+		{url: "https://evil-example.com/srl1.json", want: false},
+		{url: "http://10.0.0.1:8080/srl1.json", want: true},
+		{url: "http://10.0.0.1/srl1.json", want: false},
+		{url: "http://kubernetes.default.svc/api", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			if got := urlHostAllowed(u, hosts); got != tt.want {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %v\nexpected:%v", tt.url, got, tt.want)
+			}
+		},
+		)
+	}
+}
+
+func TestLayeredStartupConfig(t *testing.T) {
+	clientObjs := []runtime.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: defaultNamespace},
+			Data:       map[string]string{"base.json": `{"system": {}}`, "acl.txt": "set / acl", "notes.txt": "lab notes"},
+		},
+	}
+
+	tests := []struct {
+		desc     string
+		sources  []srlinuxv1.StartupConfigSource
+		wantCmds []string
+		err      error
+	}{
+		{
+			desc: "JSON base with CLI overlays loaded in order",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"base.json"}}},
+				{InlineCLI: "set / system name host-name r1"},
+				{Inline: &runtime.RawExtension{Raw: []byte(`{"interface": []}`)}},
+			},
+			wantCmds: []string{
+				"load file /tmp/startup-config/000-config.json",
+				"source /tmp/startup-config/001-config.cli",
+				"load file /tmp/startup-config/002-config.json merge",
+				"commit save",
+			},
+		},
+		{
+			desc: "JSON overlay merged into JSON base",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"base.json"}}},
+				{Inline: &runtime.RawExtension{Raw: []byte(`{"system": {"name": {"host-name": "r1"}}}`)}},
+			},
+			wantCmds: []string{
+				"load file /tmp/startup-config/000-config.json",
+				"load file /tmp/startup-config/001-config.json merge",
+				"commit save",
+			},
+		},
+		{
+			desc: "JSON overlay merged into CLI base",
+			sources: []srlinuxv1.StartupConfigSource{
+				{InlineCLI: "set / system name host-name r1"},
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"base.json"}}},
+			},
+			wantCmds: []string{
+				"source /tmp/startup-config/000-config.cli",
+				"load file /tmp/startup-config/001-config.json merge",
+				"commit save",
+			},
+		},
+		{
+			desc: "config style determined by content",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"acl.txt"}}},
+			},
+			wantCmds: []string{
+				"source /tmp/startup-config/000-config.cli",
+				"commit save",
+			},
+		},
+		{
+			desc: "unknown config style",
+			sources: []srlinuxv1.StartupConfigSource{
+				{ConfigMap: &srlinuxv1.ConfigKeySelector{Name: "base", Keys: []string{"notes.txt"}}},
+			},
+			err: ErrUnknownConfigFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &SrlinuxReconciler{
+				Client: fake.NewClientBuilder().WithRuntimeObjects(clientObjs...).Build(),
+				Scheme: scheme.Scheme,
+			}
+
+			srl := &srlinuxv1.Srlinux{
+				ObjectMeta: metav1.ObjectMeta{Name: defaultCRName, Namespace: defaultNamespace},
+				Spec: srlinuxv1.SrlinuxSpec{
+					StartupConfig: &srlinuxv1.StartupConfigSpec{Sources: tt.sources, Layered: true},
+				},
+			}
+
+			err := r.createStartupConfigSecret(ctx, srl, ctrl.Log)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			fileNames, err := r.startupConfigFileNames(ctx, srl)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			cmds, err := createStartupLoadCmds(featuresFor(nil), fileNames, defaultConfigPath)
+			if err != nil || !cmp.Equal(cmds, tt.wantCmds) {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc, cmds, tt.wantCmds)
+			}
+		},
+		)
+	}
+}
+
+func TestNormalizeStartupConfigPart(t *testing.T) {
+	tests := []struct {
+		desc       string
+		part       startupConfigPart
+		wantFormat string
+		wantData   string
+		err        error
+	}{
+		{
+			desc:       "JSON by content",
+			part:       startupConfigPart{origin: "config.cfg", data: []byte(` {"system": {}}`)},
+			wantFormat: configFormatJSON,
+			wantData:   ` {"system": {}}`,
+		},
+		{
+			desc: "CLI by content",
+			part: startupConfigPart{
+				origin: "config.txt",
+				data:   []byte("# base\nset / system name host-name r1\n\n/ interface ethernet-1/1 admin-state enable\n"),
+			},
+			wantFormat: configFormatCLI,
+			wantData:   "# base\nset / system name host-name r1\n\n/ interface ethernet-1/1 admin-state enable\n",
+		},
+		{
+			desc:       "YAML converted to JSON",
+			part:       startupConfigPart{origin: "config.yaml", format: configFormatYAML, data: []byte("system:\n  name:\n    host-name: r1\n")},
+			wantFormat: configFormatJSON,
+			wantData:   `{"system":{"name":{"host-name":"r1"}}}`,
+		},
+		{
+			desc:       "YAML by content",
+			part:       startupConfigPart{origin: "config", data: []byte("system: {}\n")},
+			wantFormat: configFormatJSON,
+			wantData:   `{"system":{}}`,
+		},
+		{
+			desc: "unknown format",
+			part: startupConfigPart{origin: "config.cfg", data: []byte("hostname r1\n")},
+			err:  ErrUnknownConfigFormat,
+		},
+		{
+			desc: "empty config",
+			part: startupConfigPart{origin: "config.cfg"},
+			err:  ErrUnknownConfigFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			p, err := normalizeStartupConfigPart(tt.part)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if err == nil && (p.format != tt.wantFormat || string(p.data) != tt.wantData) {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %s %s\nexpected:%s %s",
+					tt.desc, p.format, p.data, tt.wantFormat, tt.wantData)
+			}
+		},
+		)
+	}
+}
+
+func TestCreateStartupLoadCmdsUnknownFormat(t *testing.T) {
+	_, err := createStartupLoadCmds(featuresFor(nil), []string{"config.cfg"}, defaultConfigPath)
+	if !errors.Is(err, ErrUnknownConfigFormat) {
+		t.Fatalf("expected unknown format error, got: %v", err)
+	}
+}
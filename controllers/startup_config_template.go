@@ -0,0 +1,86 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// startupConfigTemplateData is the data startup config templates are rendered with.
+type startupConfigTemplateData struct {
+	Name        string
+	Namespace   string
+	PodIP       string
+	Interfaces  []srlinuxv1.InterfaceSpec
+	Labels      map[string]string
+	Annotations map[string]string
+	Values      map[string]any
+}
+
+// startupConfigTemplateDataFor creates the template data for the node.
+// Values from the values config map are overridden by the values set in the spec.
+func (r *SrlinuxReconciler) startupConfigTemplateDataFor(
+	ctx context.Context,
+	s *srlinuxv1.Srlinux,
+	podIP string,
+) (*startupConfigTemplateData, error) {
+	d := &startupConfigTemplateData{
+		Name:        s.Name,
+		Namespace:   s.Namespace,
+		PodIP:       podIP,
+		Interfaces:  s.Spec.Interfaces,
+		Labels:      s.Labels,
+		Annotations: s.Annotations,
+		Values:      map[string]any{},
+	}
+
+	if name := s.Spec.StartupConfig.ValuesConfigMap; name != "" {
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: s.Namespace}, cm); err != nil {
+			return nil, err
+		}
+
+		for k, v := range cm.Data {
+			d.Values[k] = v
+		}
+	}
+
+	if v := s.Spec.StartupConfig.Values; v != nil && v.Raw != nil {
+		values := map[string]any{}
+		if err := json.Unmarshal(v.Raw, &values); err != nil {
+			return nil, fmt.Errorf("%w: values: %w", ErrStartupConfigSource, err)
+		}
+
+		mergeJSON(d.Values, values)
+	}
+
+	return d, nil
+}
+
+// renderStartupConfigPart renders the startup config part as a template.
+// Missing keys fail the rendering, so that typos in variable names are not rendered as empty strings.
+func renderStartupConfigPart(p startupConfigPart, data *startupConfigTemplateData) (startupConfigPart, error) {
+	tmpl, err := template.New(p.origin).Option("missingkey=error").Parse(string(p.data))
+	if err != nil {
+		return p, fmt.Errorf("%w: %s: %w", ErrStartupConfigSource, p.origin, err)
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return p, fmt.Errorf("%w: %s: %w", ErrStartupConfigSource, p.origin, err)
+	}
+
+	p.data = b.Bytes()
+
+	return p, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRenderStartupConfig(t *testing.T) {
+	clientObjs := []runtime.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "fabric-values", Namespace: defaultNamespace},
+			Data:       map[string]string{"asn": "65000", "site": "lab1"},
+		},
+	}
+
+	tests := []struct {
+		desc     string
+		spec     *srlinuxv1.StartupConfigSpec
+		wantData string
+		err      error
+	}{
+		{
+			desc: "node variables and values rendered",
+			spec: &srlinuxv1.StartupConfigSpec{
+				Template:        true,
+				ValuesConfigMap: "fabric-values",
+				Values:          &runtime.RawExtension{Raw: []byte(`{"asn": 65001}`)},
+				Sources: []srlinuxv1.StartupConfigSource{
+					{InlineCLI: `set / system name host-name {{ .Name }}-{{ .Values.site }}`},
+					{InlineCLI: `set / network-instance default protocols bgp autonomous-system {{ .Values.asn }}`},
+					{InlineCLI: `{{ range .Interfaces }}set / interface {{ .Name }} description {{ index $.Labels "rack" }}{{ end }}`},
+					{InlineCLI: `set / system information location {{ .PodIP }}`},
+				},
+			},
+			wantData: "set / system name host-name srlinux-test-lab1\n" +
+				"set / network-instance default protocols bgp autonomous-system 65001\n" +
+				"set / interface ethernet-1/1 description r1\n" +
+				"set / system information location 10.0.0.1\n",
+		},
+		{
+			desc: "template not rendered unless enabled",
+			spec: &srlinuxv1.StartupConfigSpec{
+				Sources: []srlinuxv1.StartupConfigSource{
+					{InlineCLI: `set / system name host-name {{ .Name }}`},
+				},
+			},
+			wantData: "set / system name host-name {{ .Name }}\n",
+		},
+		{
+			desc: "missing value",
+			spec: &srlinuxv1.StartupConfigSpec{
+				Template: true,
+				Sources: []srlinuxv1.StartupConfigSource{
+					{InlineCLI: `set / network-instance default protocols bgp autonomous-system {{ .Values.asn }}`},
+				},
+			},
+			err: ErrStartupConfigSource,
+		},
+		{
+			desc: "invalid template",
+			spec: &srlinuxv1.StartupConfigSpec{
+				Template: true,
+				Sources: []srlinuxv1.StartupConfigSource{
+					{InlineCLI: `set / system name host-name {{ .Name `},
+				},
+			},
+			err: ErrStartupConfigSource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &SrlinuxReconciler{
+				Client: fake.NewClientBuilder().WithRuntimeObjects(clientObjs...).Build(),
+				Scheme: scheme.Scheme,
+			}
+
+			srl := &srlinuxv1.Srlinux{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      defaultCRName,
+					Namespace: defaultNamespace,
+					Labels:    map[string]string{"rack": "r1"},
+				},
+				Spec: srlinuxv1.SrlinuxSpec{
+					Interfaces:    []srlinuxv1.InterfaceSpec{{Name: "ethernet-1/1"}},
+					StartupConfig: tt.spec,
+				},
+			}
+
+			files, err := r.renderStartupConfig(ctx, srl, "10.0.0.1")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if data := string(files["config.cli"]); err == nil && !cmp.Equal(data, tt.wantData) {
+				t.Fatalf("%s: unexpected rendered config\n%s", tt.desc, cmp.Diff(tt.wantData, data))
+			}
+		},
+		)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestLoadStartupConfig(t *testing.T) {
+	f := featuresFor(nil)
+	checkpoint := fmt.Sprintf(f.checkpointCmd, preLoadCheckpoint)
+	loadCheckpoint := fmt.Sprintf(f.loadCheckpointCmd, preLoadCheckpoint)
+	loadConfig := fmt.Sprintf(f.loadJSONCmd, defaultConfigPath+"/config.json")
+
+	tests := []struct {
+		desc       string
+		results    map[string]string
+		failed     map[string]int
+		wantSent   []string
+		wantFailed string
+	}{
+		{
+			desc:     "loaded after the checkpoint",
+			wantSent: []string{f.getCheckpointsCmd, checkpoint, loadConfig, f.saveCmd},
+		},
+		{
+			desc:     "checkpoint of the first attempt reused",
+			results:  map[string]string{f.getCheckpointsCmd: "checkpoint 0 {\n name " + preLoadCheckpoint + "\n}"},
+			wantSent: []string{f.getCheckpointsCmd, loadConfig, f.saveCmd},
+		},
+		{
+			desc:       "checkpoint failed",
+			failed:     map[string]int{checkpoint: 1},
+			wantSent:   []string{f.getCheckpointsCmd, checkpoint},
+			wantFailed: checkpoint,
+		},
+		{
+			desc:       "load failed and candidate discarded",
+			failed:     map[string]int{loadConfig: 1},
+			wantSent:   []string{f.getCheckpointsCmd, checkpoint, loadConfig, f.discardCmd},
+			wantFailed: loadConfig,
+		},
+		{
+			desc:       "commit succeeded, save failed and config restored",
+			failed:     map[string]int{f.saveCmd: 1},
+			wantSent:   []string{f.getCheckpointsCmd, checkpoint, loadConfig, f.saveCmd, loadCheckpoint, f.saveCmd},
+			wantFailed: f.saveCmd,
+		},
+		{
+			desc:   "restore failed and candidate discarded",
+			failed: map[string]int{f.saveCmd: 2}, //nolint:mnd
+			wantSent: []string{
+				f.getCheckpointsCmd, checkpoint, loadConfig, f.saveCmd, loadCheckpoint, f.saveCmd, f.discardCmd,
+			},
+			wantFailed: f.saveCmd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			d := &fakeConfigSession{results: tt.results, failed: tt.failed}
+
+			err := loadStartupConfig(ctx, d, f, []string{"config.json"}, ctrl.Log)
+
+			var failed string
+
+			var lerr *startupConfigLoadError
+			if errors.As(err, &lerr) {
+				failed = lerr.cmd
+			} else if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if !cmp.Equal(d.sent, tt.wantSent) || failed != tt.wantFailed {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %+v %q\nexpected:%+v %q",
+					tt.desc, d.sent, failed, tt.wantSent, tt.wantFailed)
+			}
+		},
+		)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	storageVolName = "storage"
+	// storageOwnerLabel marks the claims created by the controller with the name of the Srlinux they store state of.
+	storageOwnerLabel = "kne.srlinux.dev/storage-of"
+	// persistentStorageEnv is set for the node with persistent storage,
+	// so that the entrypoint keeps the persisted config instead of copying the startup config over it.
+	persistentStorageEnv = "SRL_PERSISTENT_STORAGE"
+)
+
+// storageMounts maps sub paths of the storage volume to the directories they are mounted to.
+//
+//nolint:gochecknoglobals
+var storageMounts = []struct {
+	subPath   string
+	mountPath string
+}{
+	{subPath: "etc", mountPath: "/etc/opt/srlinux"},
+	{subPath: "log", mountPath: "/var/log/srlinux"},
+}
+
+// storageClaimName returns the name of the claim holding the node state.
+func storageClaimName(s *srlinuxv1.Srlinux) string {
+	if s.Spec.Storage.ClaimName != "" {
+		return s.Spec.Storage.ClaimName
+	}
+
+	return fmt.Sprintf("%s-storage", s.Name)
+}
+
+// handleSrlinuxStorage ensures the claim holding the node state exists when the persistent storage is defined.
+// The claim created by the controller is owned by the Srlinux object when the retention policy is Delete,
+// and is released from the ownership, so that it outlives the Srlinux object, when the policy is Retain.
+// Existing claims referenced by name are used as is.
+func (r *SrlinuxReconciler) handleSrlinuxStorage(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+) error {
+	if srlinux.Spec.Storage == nil {
+		if srlinux.Status.StorageClaim != "" {
+			srlinux.Status.StorageClaim = ""
+			*update = true
+		}
+
+		return nil
+	}
+
+	name := storageClaimName(srlinux)
+
+	if srlinux.Status.StorageClaim != name {
+		srlinux.Status.StorageClaim = name
+		*update = true
+	}
+
+	if srlinux.Spec.Storage.ClaimName != "" {
+		return nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: srlinux.Namespace}, pvc)
+	if k8serrors.IsNotFound(err) {
+		pvc, err = r.claimForSrlinux(srlinux)
+		if err != nil {
+			return err
+		}
+
+		log.Info("creating a new persistent volume claim", "claim", name)
+
+		return r.Create(ctx, pvc)
+	}
+
+	if err != nil {
+		log.Error(err, "failed to get persistent volume claim")
+
+		return err
+	}
+
+	// claims with the same name not created for this Srlinux are left intact
+	if pvc.Labels[storageOwnerLabel] != srlinux.Name {
+		return nil
+	}
+
+	if r.syncClaimOwnership(srlinux, pvc) {
+		log.Info("updating persistent volume claim ownership", "claim", name,
+			"retention policy", srlinux.Spec.Storage.GetRetentionPolicy())
+
+		return r.Update(ctx, pvc)
+	}
+
+	return nil
+}
+
+// claimForSrlinux returns the claim for the node state.
+func (r *SrlinuxReconciler) claimForSrlinux(s *srlinuxv1.Srlinux) (*corev1.PersistentVolumeClaim, error) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      storageClaimName(s),
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				"app":             s.Name,
+				"topo":            s.Namespace,
+				storageOwnerLabel: s.Name,
+			},
+		},
+		Spec: s.Spec.Storage.GetClaimTemplate(),
+	}
+
+	if s.Spec.Storage.GetRetentionPolicy() == srlinuxv1.StorageRetentionDelete {
+		if err := ctrl.SetControllerReference(s, pvc, r.Scheme); err != nil {
+			return nil, err
+		}
+	}
+
+	return pvc, nil
+}
+
+// syncClaimOwnership sets or removes the owner reference to the Srlinux object according to the retention policy.
+// Returns true if the claim has been changed.
+func (r *SrlinuxReconciler) syncClaimOwnership(s *srlinuxv1.Srlinux, pvc *corev1.PersistentVolumeClaim) bool {
+	owned := metav1.IsControlledBy(pvc, s)
+
+	switch s.Spec.Storage.GetRetentionPolicy() {
+	case srlinuxv1.StorageRetentionRetain:
+		if !owned {
+			return false
+		}
+
+		if err := controllerutil.RemoveControllerReference(s, pvc, r.Scheme); err != nil {
+			return false
+		}
+
+		return true
+	default:
+		if owned {
+			return false
+		}
+
+		return ctrl.SetControllerReference(s, pvc, r.Scheme) == nil
+	}
+}
+
+// createStorageVolume creates a volume with the node state.
+func createStorageVolume(s *srlinuxv1.Srlinux) corev1.Volume {
+	return corev1.Volume{
+		Name: storageVolName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: storageClaimName(s),
+			},
+		},
+	}
+}
+
+// createStorageVolumeMounts creates volume mounts for the directories holding the node state.
+func createStorageVolumeMounts() []corev1.VolumeMount {
+	vms := make([]corev1.VolumeMount, 0, len(storageMounts))
+
+	for _, m := range storageMounts {
+		vms = append(vms, corev1.VolumeMount{
+			Name:      storageVolName,
+			MountPath: m.mountPath,
+			SubPath:   m.subPath,
+		})
+	}
+
+	return vms
+}
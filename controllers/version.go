@@ -0,0 +1,220 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/scrapli/scrapligo/driver/network"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var ErrVersionDiscovery = errors.New("version discovery failed")
+
+const (
+	// versionDiscoveryMaxAttempts is the number of attempts to discover the version from the node
+	// before falling back to the version parsed from the spec.
+	versionDiscoveryMaxAttempts = 5
+	// versionDiscoveryRetryInterval is the interval the version discovery is retried at.
+	versionDiscoveryRetryInterval = 30 * time.Second
+)
+
+// versionRe matches the version string in the output of `info from state system information version`,
+// e.g. `version v23.10.1-218-ga3fc1bea5a`.
+var versionRe = regexp.MustCompile(`version\s+v?(\S+)`) //nolint:gochecknoglobals
+
+// handleSrlinuxVersion records SR Linux version in the status of the Srlinux CR.
+// When the version can't be determined from the spec (e.g. latest, digests or custom tags),
+// the version is discovered from the booted node, and license selection is re-run with the discovered version.
+// If the discovered version selects a different license than the one mounted to the pod,
+// the pod is deleted so that it is re-created with the matching license; true is returned in that case.
+// A failed discovery is retried after the returned duration; after versionDiscoveryMaxAttempts
+// the version parsed from the spec is used, which is reported in the VersionDiscovered condition.
+func (r *SrlinuxReconciler) handleSrlinuxVersion(
+	ctx context.Context,
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) (bool, time.Duration) {
+	if srlinux.Status.Version != "" {
+		return false, 0
+	}
+
+	v := srlinux.Spec.GetImageVersion()
+
+	if !v.IsEngineering() || srlinux.Spec.GetVersionDiscovery() == srlinuxv1.VersionDiscoveryNone {
+		srlinux.Status.Version = v.String()
+		*update = true
+
+		return false, 0
+	}
+
+	log.Info("SR Linux version could not be determined from the spec, discovering it from the node")
+
+	ver, err := r.discoverVersion(ctx, log, srlinux, pod, v)
+	if err != nil {
+		if wait := recordVersionDiscoveryFailure(log, update, srlinux, v, err); wait > 0 {
+			return false, wait
+		}
+	} else {
+		log.Info("SR Linux version discovered", "version", ver)
+
+		srlinux.Status.Version = ver
+		srlinux.Status.VersionDiscoveryAttempts = 0
+		meta.SetStatusCondition(&srlinux.Status.Conditions, metav1.Condition{
+			Type:    srlinuxv1.ConditionVersionDiscovered,
+			Status:  metav1.ConditionTrue,
+			Reason:  srlinuxv1.ReasonDiscovered,
+			Message: fmt.Sprintf("version %s discovered from the node", ver),
+		})
+		*update = true
+	}
+
+	return r.reselectLicense(ctx, log, srlinux, pod), 0
+}
+
+// discoverVersion retrieves the SR Linux version from the node running in the pod.
+func (r *SrlinuxReconciler) discoverVersion(
+	ctx context.Context,
+	log logr.Logger,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+	v *srlinuxv1.SrlVersion,
+) (string, error) {
+	ip := r.waitPodIPReady(ctx, log, srlinux)
+
+	driver := r.waitNetworkReady(ctx, log, pod, ip)
+	if driver == nil {
+		return "", fmt.Errorf("%w: %w", ErrVersionDiscovery, ErrNetworkNotReady)
+	}
+
+	version, err := getDeviceVersion(ctx, driver, featuresFor(v), log)
+
+	r.releaseNetworkDriver(log, driver, err)
+
+	return version, err
+}
+
+// recordVersionDiscoveryFailure counts the failed version discovery and returns the time to wait
+// before the next attempt. When the attempts are exhausted, the version v parsed from the spec
+// is recorded with the VersionDiscovered condition set to False and 0 is returned.
+func recordVersionDiscoveryFailure(
+	log logr.Logger,
+	update *bool,
+	srlinux *srlinuxv1.Srlinux,
+	v *srlinuxv1.SrlVersion,
+	err error,
+) time.Duration {
+	*update = true
+	srlinux.Status.VersionDiscoveryAttempts++
+
+	if srlinux.Status.VersionDiscoveryAttempts < versionDiscoveryMaxAttempts {
+		log.Error(err, "failed to discover SR Linux version, retrying",
+			"attempts", srlinux.Status.VersionDiscoveryAttempts, "retry in", versionDiscoveryRetryInterval)
+
+		return versionDiscoveryRetryInterval
+	}
+
+	log.Error(err, "failed to discover SR Linux version, continuing with the version parsed from the spec",
+		"version", v.String())
+
+	srlinux.Status.Version = v.String()
+	meta.SetStatusCondition(&srlinux.Status.Conditions, metav1.Condition{
+		Type:   srlinuxv1.ConditionVersionDiscovered,
+		Status: metav1.ConditionFalse,
+		Reason: srlinuxv1.ReasonDiscoveryFailed,
+		Message: fmt.Sprintf("version discovery failed after %d attempts, using version %s parsed from the spec: %v",
+			srlinux.Status.VersionDiscoveryAttempts, v.String(), err),
+	})
+
+	return 0
+}
+
+// reselectLicense selects the license for the discovered version and deletes the pod
+// if the selected license differs from the license mounted to the pod. Returns true if the pod was deleted.
+func (r *SrlinuxReconciler) reselectLicense(
+	ctx context.Context,
+	log logr.Logger,
+	srlinux *srlinuxv1.Srlinux,
+	pod *corev1.Pod,
+) bool {
+	if err := r.createSecrets(ctx, srlinux, log); err != nil {
+		log.Error(err, "failed to select license for the discovered version")
+
+		return false
+	}
+
+	if srlinux.LicenseKey == "" || srlinux.LicenseKey == mountedLicenseKey(pod) {
+		return false
+	}
+
+	log.Info("license for the discovered version differs from the mounted one, re-creating the pod",
+		"license key", srlinux.LicenseKey)
+
+	if err := r.Delete(ctx, pod); err != nil {
+		log.Error(err, "failed to delete Pod")
+
+		return false
+	}
+
+	r.Sessions.closeStale(log, nodeOf(pod), "")
+
+	// the re-created pod needs to get startup config provisioned again
+	srlinux.Status.StartupConfig = srlinuxv1.StartupConfigStatus{}
+	srlinux.Status.Ready = false
+
+	return true
+}
+
+// mountedLicenseKey returns the key of the license secret mounted to the pod.
+func mountedLicenseKey(pod *corev1.Pod) string {
+	for _, v := range pod.Spec.Volumes {
+		if v.Name == licensesVolName && v.Secret != nil && len(v.Secret.Items) > 0 {
+			return v.Secret.Items[0].Key
+		}
+	}
+
+	return ""
+}
+
+// getDeviceVersion retrieves the version string of the software running on the node.
+func getDeviceVersion(
+	_ context.Context,
+	d *network.Driver,
+	f *srlFeatures,
+	log logr.Logger,
+) (string, error) {
+	r, err := d.SendCommand(f.getVersionCmd)
+	if err != nil {
+		log.Error(err, "failed to send command")
+
+		return "", err
+	}
+
+	if r.Failed != nil {
+		return "", r.Failed
+	}
+
+	return parseDeviceVersion(r.Result)
+}
+
+// parseDeviceVersion extracts the version from the output of the version command.
+func parseDeviceVersion(out string) (string, error) {
+	m := versionRe.FindStringSubmatch(out)
+	if m == nil {
+		return "", fmt.Errorf("%w: version not found in %q", ErrVersionDiscovery, out)
+	}
+
+	return m[1], nil
+}
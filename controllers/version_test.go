@@ -0,0 +1,114 @@
+// Copyright 2022 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestParseDeviceVersion(t *testing.T) {
+	tests := []struct {
+		desc string
+		got  string
+		want string
+		err  error
+	}{
+		{
+			desc: "release version",
+			got: `    system {
+        information {
+            version v23.10.1-218-ga3fc1bea5a
+        }
+    }`,
+			want: "23.10.1-218-ga3fc1bea5a",
+		},
+		{
+			desc: "flat output",
+			got:  "/ system information version v24.3.2-118-g706b4f0d99",
+			want: "24.3.2-118-g706b4f0d99",
+		},
+		{
+			desc: "no version",
+			got:  "    system {\n    }",
+			err:  ErrVersionDiscovery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			v, err := parseDeviceVersion(tt.got)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s: unexpected error: %v", tt.desc, err)
+			}
+
+			if !cmp.Equal(v, tt.want) {
+				t.Fatalf(
+					"%s: actual and expected inputs do not match\nactual: %+v\nexpected:%+v",
+					tt.desc,
+					v,
+					tt.want,
+				)
+			}
+		},
+		)
+	}
+}
+
+func TestRecordVersionDiscoveryFailure(t *testing.T) {
+	v := &srlinuxv1.SrlVersion{Major: "0"}
+
+	tests := []struct {
+		desc        string
+		attempts    int32
+		wantWait    time.Duration
+		wantVersion string
+		wantCond    metav1.ConditionStatus
+	}{
+		{
+			desc:     "first failure is retried",
+			wantWait: versionDiscoveryRetryInterval,
+		},
+		{
+			desc:     "failure before the attempts are exhausted is retried",
+			attempts: versionDiscoveryMaxAttempts - 2,
+			wantWait: versionDiscoveryRetryInterval,
+		},
+		{
+			desc:        "spec version is used once the attempts are exhausted",
+			attempts:    versionDiscoveryMaxAttempts - 1,
+			wantVersion: "0",
+			wantCond:    metav1.ConditionFalse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			update := false
+			srl := &srlinuxv1.Srlinux{Status: srlinuxv1.SrlinuxStatus{VersionDiscoveryAttempts: tt.attempts}}
+
+			wait := recordVersionDiscoveryFailure(ctrl.Log, &update, srl, v, ErrVersionDiscovery)
+
+			var cond metav1.ConditionStatus
+			if c := meta.FindStatusCondition(srl.Status.Conditions, srlinuxv1.ConditionVersionDiscovered); c != nil {
+				cond = c.Status
+			}
+
+			if wait != tt.wantWait || srl.Status.Version != tt.wantVersion || cond != tt.wantCond || !update ||
+				srl.Status.VersionDiscoveryAttempts != tt.attempts+1 {
+				t.Fatalf("%s: actual and expected inputs do not match\nactual: %v %q %q\nexpected:%v %q %q",
+					tt.desc, wait, srl.Status.Version, cond, tt.wantWait, tt.wantVersion, tt.wantCond)
+			}
+		},
+		)
+	}
+}
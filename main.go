@@ -26,7 +26,12 @@ import (
 	//+kubebuilder:scaffold:imports
 )
 
-const ctrlManagerPort = 9443
+const (
+	ctrlManagerPort = 9443
+
+	// podNamespaceEnv is the env var populated with the controller's pod namespace via downward API.
+	podNamespaceEnv = "POD_NAMESPACE"
+)
 
 var (
 	scheme   = runtime.NewScheme()        //nolint:gochecknoglobals
@@ -48,6 +53,14 @@ func main() { //nolint:funlen
 
 	var probeAddr string
 
+	var controllerNamespace string
+
+	var licenseSources string
+
+	var imagePullSecret string
+
+	var startupConfigURLHosts string
+
 	flag.StringVar(
 		&metricsAddr,
 		"metrics-bind-address",
@@ -66,6 +79,22 @@ func main() { //nolint:funlen
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 
+	flag.StringVar(&controllerNamespace, "controller-namespace", getControllerNamespace(),
+		"The namespace the controller runs in and where the srlinux-licenses secret is looked up. "+
+			"Defaults to the value of the "+podNamespaceEnv+" env var or "+controllers.DefaultControllerNamespace+".")
+
+	flag.StringVar(&licenseSources, "license-sources", "",
+		"Comma separated list of additional secrets to collect SR Linux licenses from "+
+			"in the form of namespace/name. When the name is omitted, srlinux-licenses is used.")
+
+	flag.StringVar(&imagePullSecret, "image-pull-secret", "",
+		"Name of the image pull secret in the controller's namespace that is copied to lab namespaces "+
+			"and used to pull SR Linux images.")
+
+	flag.StringVar(&startupConfigURLHosts, "startup-config-url-hosts", "",
+		"Comma separated list of hosts the startup config URL sources may be fetched from, "+
+			"e.g. config-server.lab.svc or *.example.com. URL sources are refused when empty.")
+
 	opts := zap.Options{
 		Development: true,
 	}
@@ -76,6 +105,12 @@ func main() { //nolint:funlen
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	licenseSrcs, err := controllers.ParseLicenseSources(licenseSources)
+	if err != nil {
+		setupLog.Error(err, "unable to parse license sources")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
@@ -93,13 +128,36 @@ func main() { //nolint:funlen
 		os.Exit(1)
 	}
 
+	// management sessions to the nodes are shared by the controllers,
+	// idle sessions are kept alive while the manager runs and closed when it stops
+	sessions := controllers.NewSessionPool()
+
+	if err = mgr.Add(sessions); err != nil {
+		setupLog.Error(err, "unable to set up management sessions")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.SrlinuxReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		ControllerNamespace:   controllerNamespace,
+		LicenseSources:        licenseSrcs,
+		ImagePullSecret:       imagePullSecret,
+		StartupConfigURLHosts: controllers.ParseURLHosts(startupConfigURLHosts),
+		Sessions:              sessions,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Srlinux")
 		os.Exit(1)
 	}
+
+	if err = (&controllers.SrlinuxCommandReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Sessions: sessions,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SrlinuxCommand")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -119,3 +177,13 @@ func main() { //nolint:funlen
 		os.Exit(1)
 	}
 }
+
+// getControllerNamespace returns the namespace the controller pod runs in
+// as exposed via downward API, or the default controller namespace.
+func getControllerNamespace() string {
+	if ns := os.Getenv(podNamespaceEnv); ns != "" {
+		return ns
+	}
+
+	return controllers.DefaultControllerNamespace
+}